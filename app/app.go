@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,49 +20,62 @@ const defaultTitle = `
                                                                  
 `
 
-var componentDupCheck map[string]int = map[string]int{}
-var singletonComponentDupCheck map[string]int = map[string]int{}
-
 type RootComponent struct {
 	startTime               *time.Time
 	runningTime             *time.Time
 	appName                 string
 	title                   string
 	gracefulShutdownTimeout time.Duration
+	initParallelism         int
+	startupTimeout          time.Duration
+	concurrentExit          bool
+	asyncSignals            bool
+	signalHandlers          map[os.Signal]SignalAction
+	profiler                *profiler
+	shutdownMark            int
+
+	app      *AppContext
+	conf     *ConfContext
+	param    map[string]any
+	profiles []string
 
-	app   *AppContext
-	conf  *ConfContext
-	param map[string]any
-
-	componentHolder   map[string]*ComponentMeta[Component]
-	afterHandlers     map[string][]AfterInitHandler
-	beforeHandlers    map[string][]BeforeInitHandler
-	setupComponentErr []error
-	logInitFunc       LogInitFuncInterface
-	configLoaders     []ConfigLoader
-	logger            Logger
-	runningWg         sync.WaitGroup
-	exitNotifyCh      chan string
-	exitFinishedCh    chan struct{}
-	initializedCtx    context.Context
-	initializedCancel context.CancelFunc
-	initialized       bool
-	rootCtx           context.Context
-	rootCtxCancel     context.CancelFunc
+	componentHolder            map[string]*ComponentMeta[Component]
+	conditionalComponents      []*ComponentMeta[Component] // held in registration order when conditions match
+	componentDupCheck          map[string]int
+	singletonComponentDupCheck map[string]int
+	afterHandlers              map[string][]AfterInitHandler
+	beforeHandlers             map[string][]BeforeInitHandler
+	setupComponentErr          []error
+	logInitFunc                LogInitFuncInterface
+	configLoaders              []ConfigLoader
+	configDefaults             []*Conf
+	modulePrefixes             []string
+	logger                     Logger
+	runningWg                  sync.WaitGroup
+	exitNotifyCh               chan string
+	exitFinishedCh             chan struct{}
+	initializedCtx             context.Context
+	initializedCancel          context.CancelFunc
+	initialized                bool
+	rootCtx                    context.Context
+	rootCtxCancel              context.CancelFunc
 }
 
 func App(name string, ctx ...context.Context) *RootComponent {
 	now := time.Now()
 	root := &RootComponent{
-		startTime:               &now,
-		appName:                 name,
-		gracefulShutdownTimeout: 0,
-		componentHolder:         map[string]*ComponentMeta[Component]{},
-		beforeHandlers:          map[string][]BeforeInitHandler{},
-		afterHandlers:           map[string][]AfterInitHandler{},
-		param:                   map[string]any{},
-		exitNotifyCh:            make(chan string),
-		exitFinishedCh:          make(chan struct{}),
+		startTime:                  &now,
+		appName:                    name,
+		gracefulShutdownTimeout:    0,
+		componentHolder:            map[string]*ComponentMeta[Component]{},
+		componentDupCheck:          map[string]int{},
+		singletonComponentDupCheck: map[string]int{},
+		beforeHandlers:             map[string][]BeforeInitHandler{},
+		afterHandlers:              map[string][]AfterInitHandler{},
+		param:                      map[string]any{},
+		signalHandlers:             defaultSignalHandlers(),
+		exitNotifyCh:               make(chan string, 1),
+		exitFinishedCh:             make(chan struct{}, 1),
 	}
 	root.initializedCtx, root.initializedCancel = context.WithCancel(context.Background())
 	if len(ctx) > 1 {
@@ -84,54 +98,103 @@ func App(name string, ctx ...context.Context) *RootComponent {
 }
 
 func (r *RootComponent) Start() (exitCode int) {
+	err := r.Run()
+	var se *StartError
+	if errors.As(err, &se) {
+		return se.ExitCode()
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// Run is same as Start but returns *StartError instead of exit code when app failed
+func (r *RootComponent) Run() error {
 	defer r.rootCtxCancel()
+	err := r.startup(r.rootCtx)
+	if err != nil {
+		return err
+	}
+	return r.run(true)
+}
+
+// startup loads config and logger, then initializes all components
+func (r *RootComponent) startup(ctx context.Context) (err error) {
+	defer func() {
+		if err != nil {
+			r.flushFailedProfile()
+		}
+	}()
+	if r.startupTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.startupTimeout)
+		defer cancel()
+	}
 	r.printStart()
-	err := r.initConf()
+	end := r.profiler.span(profileConfig, "load config")
+	err = r.initConf()
+	end()
 	if err != nil {
 		fmt.Println("failed to initialize config:", err.Error())
-		exitCode = 1
-		return
+		return newStartError(PhaseConfig, "", err)
 	}
+	end = r.profiler.span(profileLog, "init log")
 	err = r.initLog()
+	end()
 	if err != nil {
 		fmt.Println("failed to initialize log:", err.Error())
-		exitCode = 2
-		return
+		return newStartError(PhaseLog, "", err)
+	}
+	if profiles := r.conf.Profiles(); len(profiles) > 0 {
+		r.logger.Infof("active profiles: %s", strings.Join(profiles, ","))
 	}
 	err = r.initAppContext()
 	if err != nil {
 		r.logger.Error("failed to initialize app context:", err.Error())
-		exitCode = 3
-		return
+		se := newStartError(PhaseSetup, "", err)
+		se.exitCode = 3
+		return se
 	}
 	if len(r.setupComponentErr) > 0 {
-		r.logger.Error("failed to setup component:", errors.Join(r.setupComponentErr...))
-		exitCode = 4
-		return
+		err = errors.Join(r.setupComponentErr...)
+		r.logger.Error("failed to setup component:", err)
+		return newStartError(PhaseSetup, "", err)
 	}
-	err = r.initComponents()
+	r.evaluateConditions()
+	err = r.initComponents(ctx)
 	if err != nil {
 		r.logger.Error("failed to initialize components:", err.Error())
-		exitCode = 4
-		return
+		return asStartError(PhaseInit, err)
 	}
 	r.initializedCancel()
 	now := time.Now()
 	r.runningTime = &now
 	r.printTitle()
-	err = r.runAll()
-	if err != nil {
-		r.logger.Error("failed to run components:", err.Error())
-		exitCode = 5
+	r.logProfile("startup profile", 0)
+	r.shutdownMark = r.profiler.mark()
+	return nil
+}
+
+// run blocks until all runnable components stopped, then closes all components
+func (r *RootComponent) run(signals bool) error {
+	var result error
+	runErr := r.runAll(signals)
+	if runErr != nil {
+		r.logger.Error("failed to run components:", runErr.Error())
+		result = asStartError(PhaseRun, runErr)
 	}
-	err = r.closeComponents()
-	if err != nil {
-		r.logger.Error("failed to close components:", err.Error())
-		exitCode = 6
-		return
+	closeErr := r.closeComponents()
+	r.logProfile("run and shutdown profile", r.shutdownMark)
+	if err := r.profiler.writeTrace(); err != nil {
+		r.logger.Error("failed to write trace file:", err.Error())
+	}
+	if closeErr != nil {
+		r.logger.Error("failed to close components:", closeErr.Error())
+		return newStartError(PhaseClose, "", errors.Join(runErr, closeErr))
 	}
 	r.logger.Info("app exit successfully")
-	return
+	return result
 }
 
 func (r *RootComponent) Exit(msg ...string) {
@@ -159,10 +222,33 @@ func (r *RootComponent) WithGracefulShutdownTimeout(d time.Duration) {
 	r.gracefulShutdownTimeout = d
 }
 
+// WithStartupTimeout limits the time from Start to all components initialized,
+// Start fails with exit code 4 when it expires
+func (r *RootComponent) WithStartupTimeout(d time.Duration) {
+	r.startupTimeout = d
+}
+
+// WithParallelInit initializes components level by level in topological order,
+// components of the same level are initialized concurrently by at most n goroutines
+func (r *RootComponent) WithParallelInit(n int) {
+	r.initParallelism = n
+}
+
+// WithConcurrentExit calls OnExit of all runnable components at once instead of in reverse dependency order
+func (r *RootComponent) WithConcurrentExit() {
+	r.concurrentExit = true
+}
+
 func (r *RootComponent) WithParam(name string, value any) {
 	r.param[name] = value
 }
 
+// WithActiveProfiles activates profiles in precedence order, later profiles win, values can be separated by comma,
+// so a flag like --profiles=dev,local can be passed directly. It takes precedence over ProfilesEnv and ProfilesConfigKey
+func (r *RootComponent) WithActiveProfiles(profiles ...string) {
+	r.profiles = parseProfiles(append(r.profiles, profiles...)...)
+}
+
 func (r *RootComponent) WithLogger(logInitFunc LogInitFunc) {
 	r.logInitFunc = logInitFunc
 }
@@ -172,27 +258,87 @@ func (r *RootComponent) WithConfigLoader(loader ConfigLoader) {
 }
 
 func (r *RootComponent) WithComponentMeta(name string, componentMeta *ComponentMeta[Component]) {
+	if componentMeta != nil && componentMeta.setupErr != nil {
+		r.setupComponentErr = append(r.setupComponentErr, componentMeta.setupErr)
+		return
+	}
 	err := componentMeta.preInit(name)
 	if err != nil {
 		r.logger.Error(err)
 		os.Exit(1)
 	}
-	r.componentHolder[componentMeta.ID()] = componentMeta
-	componentDupCheck[componentMeta.ID()] = componentDupCheck[componentMeta.ID()] + 1
+	if len(componentMeta.conditions) > 0 {
+		r.conditionalComponents = append(r.conditionalComponents, componentMeta)
+		return
+	}
+	r.holdComponent(componentMeta)
+}
+
+// holdComponent adds the component to be initialized, duplicates are counted and reported before init
+func (r *RootComponent) holdComponent(componentMeta *ComponentMeta[Component]) {
+	if _, exist := r.componentHolder[componentMeta.ID()]; !exist {
+		r.componentHolder[componentMeta.ID()] = componentMeta
+	}
+	r.componentDupCheck[componentMeta.ID()] = r.componentDupCheck[componentMeta.ID()] + 1
 	t := string(componentMeta.componentType)
-	singletonComponentDupCheck[t] = singletonComponentDupCheck[t] + 1
+	r.singletonComponentDupCheck[t] = r.singletonComponentDupCheck[t] + 1
+}
+
+// allComponents returns held components and the first one of conditional components of each id,
+// it is used to check or export wiring without evaluating conditions
+func (r *RootComponent) allComponents() map[string]*ComponentMeta[Component] {
+	components := make(map[string]*ComponentMeta[Component], len(r.componentHolder)+len(r.conditionalComponents))
+	for id, meta := range r.componentHolder {
+		components[id] = meta
+	}
+	for _, meta := range r.conditionalComponents {
+		if _, exist := components[meta.ID()]; !exist {
+			components[meta.ID()] = meta
+		}
+	}
+	return components
 }
 
 func (r *RootComponent) WithComponent(component Component, options ...ComponentMetaOption[Component]) {
-	r.WithNamedComponent("", component)
+	r.WithNamedComponent("", component, options...)
+}
+
+// WithPrototypeComponent registers a component built by factory for every injection point and every GetComponent call,
+// instances fetched by GetComponent are closed at shutdown, or earlier by ReleaseComponent
+func (r *RootComponent) WithPrototypeComponent(factory func() Component, options ...ComponentMetaOption[Component]) {
+	r.WithNamedPrototypeComponent("", factory, options...)
+}
+
+func (r *RootComponent) WithNamedPrototypeComponent(name string, factory func() Component, options ...ComponentMetaOption[Component]) {
+	r.WithNamedComponent(name, factory(), append(options, WithPrototype[Component](factory))...)
 }
 
 func (r *RootComponent) WithNamedComponent(name string, component Component, options ...ComponentMetaOption[Component]) {
-	typeName, types, instances, fields, err := resolveDependencies(component)
+	meta, err := buildComponentMeta(component, options...)
 	if err != nil {
 		r.setupComponentErr = append(r.setupComponentErr, err)
 		return
 	}
+	if componentProvider, ok := component.(ComponentProvider); ok {
+		components := componentProvider.EkitComponents()
+		if len(components) > 0 {
+			for _, c := range components {
+				r.WithComponent(c)
+			}
+		}
+	}
+	if name == "" {
+		name = meta.componentType.ShortName()
+	}
+	r.WithComponentMeta(name, meta)
+}
+
+// buildComponentMeta resolves dependencies of component from its tags and extensions
+func buildComponentMeta(component Component, options ...ComponentMetaOption[Component]) (*ComponentMeta[Component], error) {
+	typeName, types, instances, fields, err := resolveDependencies(component)
+	if err != nil {
+		return nil, err
+	}
 	if dependenciesExtendComponent, ok := component.(DependenciesExtendComponent); ok {
 		ts, its := dependenciesExtendComponent.EkitDependencies()
 		if len(ts) > 0 {
@@ -201,23 +347,12 @@ func (r *RootComponent) WithNamedComponent(name string, component Component, opt
 		if len(its) > 0 {
 			instances = append(instances, its...)
 		}
-	}
-	if componentProvider, ok := component.(ComponentProvider); ok {
-		components := componentProvider.EkitComponents()
-		if len(components) > 0 {
-			for _, c := range components {
-				r.WithComponent(c)
-			}
-		}
+		options = append(options, withExtendDependencies[Component](ts, its))
 	}
 	options = append(options, withDependencyTypes[Component](types...),
 		withDependencies[Component](instances...),
 		withFieldInfo[Component](fields))
-	meta := NewComponentMeta(ComponentType(typeName), component, options...)
-	if name == "" {
-		name = typeName
-	}
-	r.WithComponentMeta(name, meta)
+	return NewComponentMeta(ComponentType(typeName), component, options...), nil
 }
 
 func (r *RootComponent) printStart() {
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+)
+
+// Running is the handle of an app started by StartAsync
+type Running struct {
+	root *RootComponent
+	done chan struct{}
+	err  error
+}
+
+// StartAsync returns once all components are initialized or startup failed,
+// ctx only bounds the startup, the app keeps running until Stop is called or all runnable components stopped.
+// OS signals are not handled unless WithAsyncSignals is set.
+func (r *RootComponent) StartAsync(ctx context.Context) (*Running, error) {
+	err := r.startup(ctx)
+	if err != nil {
+		r.rootCtxCancel()
+		return nil, err
+	}
+	running := &Running{
+		root: r,
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(running.done)
+		defer r.rootCtxCancel()
+		running.err = r.run(r.asyncSignals)
+	}()
+	return running, nil
+}
+
+// WithAsyncSignals handles OS signals in StartAsync as Start does
+func (r *RootComponent) WithAsyncSignals() {
+	r.asyncSignals = true
+}
+
+func (r *Running) App() *AppContext {
+	return r.root.app
+}
+
+// Stop exits app gracefully and waits until all components closed or ctx done
+func (r *Running) Stop(ctx context.Context) error {
+	r.root.app.Exit("app stopped")
+	select {
+	case <-r.done:
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done is closed after app stopped and all components closed
+func (r *Running) Done() <-chan struct{} {
+	return r.done
+}
+
+// Err returns the *StartError of running or closing components after Done is closed
+func (r *Running) Err() error {
+	select {
+	case <-r.done:
+		return r.err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartAsync(t *testing.T) {
+	app := App("demo")
+	var Server ComponentType = "AsyncServer"
+	app.WithComponentMeta("server", NewComponentMeta[Component](Server, &SimpleRunnableComponent{}))
+	running, err := app.StartAsync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-running.Done():
+		t.Fatal("app should keep running until stopped")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if running.Err() != nil {
+		t.Fatal("Err should be nil before done")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = running.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	status, _ := running.App().ComponentState(getComponentID(Server, "server"))
+	if status.State != StateStopped {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if err = running.Stop(ctx); err != nil {
+		t.Fatal("stop twice should be fine", err)
+	}
+}
+
+func TestStartAsyncFailed(t *testing.T) {
+	app := App("demo")
+	var Hanging ComponentType = "AsyncHanging"
+	app.WithComponentMeta("hanging", NewComponentMeta[Component](Hanging, &TestHangingComponent{}))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	running, err := app.StartAsync(ctx)
+	if running != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unexpected result %v, %v", running, err)
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Condition decides whether a component is kept in the graph, reason is logged when the component is dropped
+type Condition func(c *ConditionContext) (ok bool, reason string)
+
+// ConditionContext is evaluated after config loaded, components are the ones without conditions
+// and the conditional ones registered before the evaluated one and kept, Conf is under the mount prefix for module components
+type ConditionContext struct {
+	Conf   *ConfContext
+	Params *Params
+	types  *typeIndex
+}
+
+// HasComponent reports whether a component of the type is kept, short type name is resolved when it is unique
+func (c *ConditionContext) HasComponent(componentType ComponentType) bool {
+	t, err := c.types.resolveType(string(componentType))
+	return err == nil && c.types.known(t)
+}
+
+// WithCondition keeps the component only when all conditions match
+func WithCondition[T Component](conditions ...Condition) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.conditions = append(meta.conditions, conditions...)
+	}
+}
+
+// OnConfig matches when the config value of key equals value, values are compared as strings
+func OnConfig(key string, value any) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		expected := cast.ToString(value)
+		actual, err := c.Conf.Value(key).MustString()
+		if err != nil {
+			return false, fmt.Sprintf("config %s is not set, expected %s", key, expected)
+		}
+		if actual != expected {
+			return false, fmt.Sprintf("config %s is %s, expected %s", key, actual, expected)
+		}
+		return true, ""
+	}
+}
+
+// WithProfiles keeps the component only when one of profiles is active
+func WithProfiles[T Component](profiles ...string) ComponentMetaOption[T] {
+	return WithCondition[T](OnProfile(profiles...))
+}
+
+// OnProfile matches when one of profiles is active
+func OnProfile(profiles ...string) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		for _, p := range profiles {
+			if c.Conf.ProfileActive(p) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("profiles %s are not active", strings.Join(profiles, ","))
+	}
+}
+
+// OnMissingComponent matches when no component of the type is kept, see ConditionContext
+func OnMissingComponent(componentType ComponentType) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		if c.HasComponent(componentType) {
+			return false, fmt.Sprintf("component %s is present", componentType)
+		}
+		return true, ""
+	}
+}
+
+// OnPresentComponent matches when a component of the type is kept, see ConditionContext
+func OnPresentComponent(componentType ComponentType) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		if !c.HasComponent(componentType) {
+			return false, fmt.Sprintf("component %s is missing", componentType)
+		}
+		return true, ""
+	}
+}
+
+// OnEnv matches when the environment variable is set, and equals one of values if any given
+func OnEnv(name string, values ...string) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return false, fmt.Sprintf("env %s is not set", name)
+		}
+		if len(values) > 0 && !slices.Contains(values, v) {
+			return false, fmt.Sprintf("env %s is %s, expected one of %s", name, v, strings.Join(values, ","))
+		}
+		return true, ""
+	}
+}
+
+// OnPredicate matches when predicate returns true, name describes the predicate in log
+func OnPredicate(name string, predicate func(c *ConditionContext) bool) Condition {
+	return func(c *ConditionContext) (bool, string) {
+		if !predicate(c) {
+			return false, fmt.Sprintf("predicate %s is false", name)
+		}
+		return true, ""
+	}
+}
+
+// matchConditions reports the reason of the first condition not matched
+func (cm *ComponentMeta[T]) matchConditions(c *ConditionContext) (bool, string) {
+	for _, condition := range cm.conditions {
+		if ok, reason := condition(c); !ok {
+			return false, reason
+		}
+	}
+	return true, ""
+}
+
+// evaluateConditions holds components whose conditions match and drops the others,
+// components without conditions are always kept, the others are evaluated in registration order.
+// Components of the same id are alternatives, it is a duplicate only when more than one is kept
+func (r *RootComponent) evaluateConditions() {
+	c := &ConditionContext{
+		Params: r.app.params,
+		types:  newTypeIndex(r.componentHolder),
+	}
+	for _, meta := range r.conditionalComponents {
+		c.Conf = meta.confOf(r.conf)
+		if ok, reason := meta.matchConditions(c); !ok {
+			r.logger.Infof("component %s dropped: %s", meta.ID(), reason)
+			continue
+		}
+		r.holdComponent(meta)
+		c.types.add(string(meta.componentType))
+	}
+	r.conditionalComponents = nil
+}
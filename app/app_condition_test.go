@@ -0,0 +1,103 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestRedisCache struct {
+	SimpleComponent
+}
+
+type TestMemoryCache struct {
+	SimpleComponent
+}
+
+func TestConditions(t *testing.T) {
+	run := func(cacheType string, options ...ComponentMetaOption[Component]) (redis, memory, extra bool) {
+		app := App("demo")
+		app.WithConfigLoader(&TestMapConfigLoader{conf: Conf{"cache": map[string]any{"type": cacheType}}})
+		app.WithComponent(&TestRedisCache{}, WithCondition[Component](OnConfig("cache.type", "redis")))
+		app.WithComponent(&TestMemoryCache{}, WithCondition[Component](OnMissingComponent("TestRedisCache")))
+		app.WithNamedComponent("extra", &TestMemoryCache{}, options...)
+		app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+			_, err := app.GetComponentByIdE(testID(&TestRedisCache{}))
+			redis = err == nil
+			_, err = app.GetComponentByIdE(testID(&TestMemoryCache{}))
+			memory = err == nil
+			_, err = app.GetComponentByIdE(getComponentID(ComponentTypeOf(&TestMemoryCache{}), "extra"))
+			extra = err == nil
+		}})
+		if err := app.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if redis, memory, _ := run("redis"); !redis || memory {
+		t.Fatalf("unexpected components redis %v, memory %v", redis, memory)
+	}
+	if redis, memory, _ := run("memory"); redis || !memory {
+		t.Fatalf("unexpected components redis %v, memory %v", redis, memory)
+	}
+	t.Setenv("EKIT_TEST_CONDITION", "on")
+	if _, _, extra := run("redis", WithCondition[Component](OnEnv("EKIT_TEST_CONDITION", "on"))); !extra {
+		t.Fatal("component with matched env condition dropped")
+	}
+	if _, _, extra := run("redis", WithCondition[Component](OnEnv("EKIT_TEST_CONDITION", "off"))); extra {
+		t.Fatal("component with unmatched env condition kept")
+	}
+	if _, _, extra := run("redis", WithCondition[Component](OnPredicate("has redis", func(c *ConditionContext) bool {
+		return c.HasComponent("TestRedisCache")
+	}))); !extra {
+		t.Fatal("component with matched predicate dropped")
+	}
+}
+
+func TestConditionsSeeLaterComponents(t *testing.T) {
+	app := App("demo")
+	app.WithComponent(&TestMemoryCache{}, WithCondition[Component](OnMissingComponent("TestRedisCache")))
+	app.WithComponent(&TestRedisCache{})
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		if _, err := app.GetComponentByIdE(testID(&TestMemoryCache{})); err == nil {
+			t.Error("memory cache kept while redis cache is registered later")
+		}
+		err := app.RegisterNamed("runtime", &TestMemoryCache{}, WithCondition[Component](OnMissingComponent("TestRedisCache")))
+		if !errors.Is(err, ErrConditionNotMatched) {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := app.RegisterNamed("present", &TestMemoryCache{}, WithCondition[Component](OnPresentComponent("TestRedisCache"))); err != nil {
+			t.Error(err)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type TestModeStore struct {
+	SimpleComponent
+	Mode string
+}
+
+func TestConditionAlternatives(t *testing.T) {
+	run := func(profiles ...string) (*TestModeStore, error) {
+		app := App("demo")
+		app.WithActiveProfiles(profiles...)
+		app.WithComponent(&TestModeStore{Mode: "dev"}, WithProfiles[Component]("dev"))
+		app.WithComponent(&TestModeStore{Mode: "prod"}, WithProfiles[Component]("prod"))
+		var store *TestModeStore
+		app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+			store, _ = app.GetComponent("TestModeStore", "TestModeStore").(*TestModeStore)
+		}})
+		return store, app.Run()
+	}
+	if store, err := run("dev"); err != nil || store == nil || store.Mode != "dev" {
+		t.Fatalf("dev alternative should be kept: %+v, %v", store, err)
+	}
+	if store, err := run("prod"); err != nil || store == nil || store.Mode != "prod" {
+		t.Fatalf("prod alternative should be kept: %+v, %v", store, err)
+	}
+	if _, err := run("dev", "prod"); !errors.Is(err, ErrDuplicateComponent) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -15,11 +16,31 @@ import (
 type ConfigLoader interface {
 	Load(updater *ConfigUpdater) error
 }
+
+// ReloadableConfigLoader reloads config on demand, loaders not implementing it are skipped,
+// loading them again would start their watchers twice
+type ReloadableConfigLoader interface {
+	ConfigLoader
+	Reload(updater *ConfigUpdater) error
+}
 type CloseableConfigLoader interface {
 	ConfigLoader
 	Stop() error
 }
 
+const (
+	// ProfilesEnv lists active profiles separated by comma, used when no profile is set by WithActiveProfiles
+	ProfilesEnv = "EKIT_PROFILES"
+	// ProfilesConfigKey lists active profiles in config, used when no profile is set by WithActiveProfiles or ProfilesEnv
+	ProfilesConfigKey = "app.profiles"
+)
+
+// ProfileConfigLoader loads config of active profiles on top of its base config, later profiles win
+type ProfileConfigLoader interface {
+	ConfigLoader
+	LoadProfiles(updater *ConfigUpdater, profiles []string) error
+}
+
 type ConfigUpdater struct {
 	lock       sync.RWMutex
 	target     *Conf
@@ -45,6 +66,8 @@ type ConfContext struct {
 	mustCloseLoaders []CloseableConfigLoader
 	config           *Conf
 	configUpdater    *ConfigUpdater
+	profiles         []string
+	prefix           string
 }
 
 func NewConfContext(loaders ...ConfigLoader) *ConfContext {
@@ -58,7 +81,7 @@ func NewConfContext(loaders ...ConfigLoader) *ConfContext {
 }
 
 func (c *ConfContext) Close() error {
-	if c != nil && c.configUpdater != nil {
+	if c != nil && c.configUpdater != nil && c.prefix == "" {
 		c.configUpdater.stop()
 		var errs []error
 		for _, l := range c.mustCloseLoaders {
@@ -81,15 +104,103 @@ func (c *ConfContext) initConf() error {
 
 		}
 	}
+	if len(c.profiles) == 0 {
+		value := c.config.Value(ProfilesConfigKey)
+		if lst, err := value.MustSlice(); err == nil {
+			for _, v := range lst {
+				c.profiles = append(c.profiles, parseProfiles(v.String())...)
+			}
+		} else {
+			c.profiles = parseProfiles(value.String())
+		}
+	}
+	if len(c.profiles) == 0 {
+		return nil
+	}
+	for _, loader := range c.loaders {
+		if pl, ok := loader.(ProfileConfigLoader); ok {
+			if err := pl.LoadProfiles(c.configUpdater, c.profiles); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
+// Profiles returns active profiles in precedence order, later profiles win
+func (c *ConfContext) Profiles() []string {
+	return slices.Clone(c.profiles)
+}
+
+func (c *ConfContext) ProfileActive(profile string) bool {
+	return slices.Contains(c.profiles, profile)
+}
+
+// parseProfiles splits profiles by comma, blanks and duplicates are removed
+func parseProfiles(values ...string) []string {
+	var profiles []string
+	for _, v := range values {
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if p != "" && !slices.Contains(profiles, p) {
+				profiles = append(profiles, p)
+			}
+		}
+	}
+	return profiles
+}
+
+func (c *ConfContext) Reload() error {
+	var errs []error
+	for _, loader := range c.loaders {
+		if rl, ok := loader.(ReloadableConfigLoader); ok {
+			errs = append(errs, rl.Reload(c.configUpdater))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func (c *ConfContext) Value(key string) ConfValue {
 	c.configUpdater.lock.RLock()
 	defer c.configUpdater.lock.RUnlock()
+	if c.prefix != "" {
+		key = c.prefix + "." + key
+	}
 	return c.config.Value(key)
 }
 
+// Sub returns config under prefix, it shares loaders and updates with c and closing it does nothing
+func (c *ConfContext) Sub(prefix string) *ConfContext {
+	sub := *c
+	if c.prefix != "" {
+		prefix = c.prefix + "." + prefix
+	}
+	sub.prefix = prefix
+	return &sub
+}
+
+// Root returns config without prefix, it reads global keys from a Sub config
+func (c *ConfContext) Root() *ConfContext {
+	root := *c
+	root.prefix = ""
+	return &root
+}
+
+// withDefaults stores defaults under loaded config, loaded values win
+func (c *ConfContext) withDefaults(defaults ...*Conf) {
+	if len(defaults) == 0 {
+		return
+	}
+	c.configUpdater.lock.Lock()
+	defer c.configUpdater.lock.Unlock()
+	n := Conf{}
+	for _, d := range defaults {
+		mergo.Map(&n, d, mergo.WithOverride)
+	}
+	mergo.Map(&n, *c.config, mergo.WithOverride)
+	*c.config = n
+}
+
 type Conf map[string]any
 
 func (c *Conf) Value(fullKey string) ConfValue {
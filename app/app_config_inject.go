@@ -0,0 +1,211 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// configField is a field filled from config by tag like `ekit:"config:redis.addr;default:localhost:6379"`,
+// or from app params by tag like `ekit:"param:configPath"`
+type configField struct {
+	FieldName  string
+	Key        string
+	Param      bool
+	Default    []string
+	HasDefault bool
+	Required   bool
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func resolveConfigFields(component Component) ([]configField, error) {
+	t := reflect.TypeOf(component)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	t = t.Elem()
+	typeName := qualifiedTypeName(t)
+	var fields []configField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ekitTag := field.Tag.Get(TagEkit)
+		if ekitTag == "" {
+			continue
+		}
+		tags, err := EkitTagStr(ekitTag).Parse()
+		if err != nil {
+			return nil, errors.New(typeName + " config field parse failed: " + err.Error())
+		}
+		tag, ok := FindTag(tags, TagConfig)
+		_, isParam := FindTag(tags, TagParam)
+		if ok && isParam {
+			return nil, errors.New("config and param can not be used together: " + typeName + "." + field.Name)
+		}
+		if isParam {
+			tag, _ = FindTag(tags, TagParam)
+		} else if !ok {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, errors.New("variables must be visible to the outside when using ekit config inject: " + typeName + "." + field.Name)
+		}
+		if tag.ValueCount() != 1 || tag.Values[0] == "" {
+			return nil, errors.New("config key must be specified: " + typeName + "." + field.Name)
+		}
+		cf := configField{
+			FieldName: field.Name,
+			Key:       tag.Values[0],
+			Param:     isParam,
+			Required:  true,
+		}
+		if defaultTag, ok := FindTag(tags, TagDefault); ok {
+			cf.Default = defaultTag.Values
+			cf.HasDefault = true
+		}
+		if requiredTag, ok := FindTag(tags, TagRequired); ok && len(requiredTag.Values) > 0 {
+			b, err := strconv.ParseBool(requiredTag.Values[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid required value %q: %s.%s", requiredTag.Values[0], typeName, field.Name)
+			}
+			cf.Required = b
+		}
+		fields = append(fields, cf)
+	}
+	return fields, nil
+}
+
+// configInject fills config and param fields of inMeta before Init
+func (ci *ComponentInitializer) configInject(inMeta *ComponentMeta[Component]) error {
+	if len(inMeta.configFields) == 0 {
+		return nil
+	}
+	v := reflect.ValueOf(inMeta.component).Elem()
+	typeName := qualifiedTypeName(v.Type())
+	for _, cf := range inMeta.configFields {
+		var value ConfValue
+		source, notFoundErr := TagConfig, ErrConfigNotFound
+		if cf.Param {
+			source, notFoundErr = TagParam, ErrParamNotFound
+			p, ok := ci.app.params.Get(cf.Key)
+			value = ConfValue{value: p, notfound: !ok}
+		} else {
+			value = inMeta.confOf(ci.conf).Value(cf.Key)
+		}
+		field := v.FieldByName(cf.FieldName)
+		if value.notfound {
+			if cf.HasDefault {
+				value = defaultConfValue(field.Type(), cf.Default)
+			} else if cf.Required {
+				return fmt.Errorf("%w: %s required by %s.%s, or you can add \"default:...\" or \"required:false\" on field tag", notFoundErr, cf.Key, typeName, cf.FieldName)
+			} else {
+				continue
+			}
+		}
+		if err := setConfValue(field, value); err != nil {
+			return fmt.Errorf("%s %s can not set to %s.%s: %w", source, cf.Key, typeName, cf.FieldName, err)
+		}
+	}
+	return nil
+}
+
+// defaultConfValue splits default of slice field by comma, commas of other fields are kept
+func defaultConfValue(t reflect.Type, values []string) ConfValue {
+	if t.Kind() == reflect.Slice {
+		lst := make([]any, len(values))
+		for i, v := range values {
+			lst[i] = v
+		}
+		return ConfValue{value: lst}
+	}
+	return ConfValue{value: strings.Join(values, TagEkitValuesSep)}
+}
+
+// setConfValue converts value by the casting of ConfValue, structs and pointers are scanned
+func setConfValue(field reflect.Value, value ConfValue) error {
+	if value.value != nil && reflect.TypeOf(value.value).AssignableTo(field.Type()) {
+		field.Set(reflect.ValueOf(value.value))
+		return nil
+	}
+	if field.Type() == durationType {
+		d, err := value.MustDuration()
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		b, err := value.MustBool()
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := value.MustInt64()
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(i) {
+			return fmt.Errorf("%d overflows %s", i, field.Type())
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := value.MustUint64()
+		if err != nil {
+			return err
+		}
+		if field.OverflowUint(u) {
+			return fmt.Errorf("%d overflows %s", u, field.Type())
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := value.MustFloat64()
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.String:
+		s, err := value.MustString()
+		if err != nil {
+			return err
+		}
+		field.SetString(s)
+	case reflect.Slice:
+		lst, err := value.MustSlice()
+		if err != nil {
+			return err
+		}
+		target := reflect.MakeSlice(field.Type(), len(lst), len(lst))
+		for i, e := range lst {
+			if err = setConfValue(target.Index(i), e); err != nil {
+				return err
+			}
+		}
+		field.Set(target)
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String {
+			return value.Scan(field.Addr().Interface())
+		}
+		m, err := value.MustMap()
+		if err != nil {
+			return err
+		}
+		target := reflect.MakeMapWithSize(field.Type(), len(m))
+		for k, e := range m {
+			ev := reflect.New(field.Type().Elem()).Elem()
+			if err = setConfValue(ev, e); err != nil {
+				return err
+			}
+			target.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), ev)
+		}
+		field.Set(target)
+	default:
+		return value.Scan(field.Addr().Interface())
+	}
+	return nil
+}
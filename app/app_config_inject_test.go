@@ -0,0 +1,100 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestMapConfigLoader struct {
+	conf Conf
+}
+
+func (l *TestMapConfigLoader) Load(updater *ConfigUpdater) error {
+	return updater.UpdateConfig(&l.conf)
+}
+
+type TestRedisOptions struct {
+	DB  int  `json:"db"`
+	TLS bool `json:"tls"`
+}
+
+type TestConfigured struct {
+	SimpleComponent
+	Addr    string            `ekit:"config:redis.addr;default:localhost:6379"`
+	Port    int               `ekit:"config:redis.port"`
+	Timeout time.Duration     `ekit:"config:redis.timeout;default:3s"`
+	Hosts   []string          `ekit:"config:redis.hosts;default:a,b"`
+	Weights map[string]uint16 `ekit:"config:redis.weights"`
+	Options TestRedisOptions  `ekit:"config:redis.options"`
+	Debug   *bool             `ekit:"config:debug;required:false"`
+}
+
+type TestConfigMissing struct {
+	SimpleComponent
+	Addr string `ekit:"config:missing.addr"`
+}
+
+type TestConfigAddr struct {
+	SimpleComponent
+	Addr string `ekit:"config:redis.addr;default:localhost:6379"`
+	Port int    `ekit:"config:redis.port"`
+}
+
+type TestConfigBadRequired struct {
+	SimpleComponent
+	Addr string `ekit:"config:redis.addr;required:flase"`
+}
+
+func TestConfigInject(t *testing.T) {
+	app := App("demo")
+	app.WithConfigLoader(&TestMapConfigLoader{conf: Conf{"redis": map[string]any{
+		"port":    "6380",
+		"weights": map[string]any{"a": 1, "b": "2"},
+		"options": map[string]any{"db": 3, "tls": true},
+	}}})
+	c := &TestConfigured{}
+	app.WithComponent(c)
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Addr != "localhost:6379" || c.Port != 6380 || c.Timeout != 3*time.Second || !slices.Equal(c.Hosts, []string{"a", "b"}) ||
+		c.Weights["b"] != 2 || c.Options.DB != 3 || !c.Options.TLS || c.Debug != nil {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	app = App("demo")
+	app.WithComponent(&TestConfigMissing{})
+	if err := app.Run(); !errors.Is(err, ErrConfigNotFound) || !strings.Contains(err.Error(), "missing.addr") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	app = App("demo")
+	app.WithComponent(&TestConfigBadRequired{})
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "flase") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestConfigInjectComponentMeta(t *testing.T) {
+	app := App("demo")
+	app.WithConfigLoader(&TestMapConfigLoader{conf: Conf{"redis": map[string]any{"port": 6390}}})
+	c := &TestConfigAddr{}
+	app.WithComponentMeta("", NewComponentMeta[Component]("configured", c))
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Port != 6390 || c.Addr != "localhost:6379" {
+		t.Fatalf("unexpected config %+v", c)
+	}
+
+	app = App("demo")
+	app.WithComponentMeta("", NewComponentMeta[Component]("missing", &TestConfigMissing{}))
+	if err := app.Run(); !errors.Is(err, ErrConfigNotFound) || !strings.Contains(err.Error(), "app.TestConfigMissing") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type TestProfileServer struct {
+	SimpleComponent
+	Addr string `ekit:"config:server.addr"`
+	Port int    `ekit:"config:server.port"`
+	Mode string `ekit:"config:server.mode"`
+}
+
+func TestActiveProfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml":       "server:\n  addr: localhost\n  port: 80\n  mode: base\n",
+		"config-dev.yaml":   "server:\n  port: 8080\n  mode: dev\n",
+		"config-local.yaml": "server:\n  mode: local\n",
+		"config-env.yaml":   "server:\n  mode: env\n",
+		"app.yaml":          "app:\n  profiles: [dev, conf]\nserver:\n  addr: localhost\n  port: 80\n  mode: base\n",
+		"app-conf.yaml":     "server:\n  mode: conf\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(base string, profiles ...string) (*TestProfileServer, bool, bool) {
+		app := App("demo")
+		app.WithConfigLoader(NewFileConfigLoader(filepath.Join(dir, base)))
+		app.WithActiveProfiles(profiles...)
+		s := &TestProfileServer{}
+		app.WithComponent(s)
+		app.WithComponent(&TestRedisCache{}, WithProfiles[Component]("dev"))
+		app.WithComponent(&TestMemoryCache{}, WithProfiles[Component]("prod"))
+		var dev, prod bool
+		app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+			_, err := app.GetComponentByIdE(testID(&TestRedisCache{}))
+			dev = err == nil
+			_, err = app.GetComponentByIdE(testID(&TestMemoryCache{}))
+			prod = err == nil
+		}})
+		if err := app.Run(); err != nil {
+			t.Fatal(err)
+		}
+		return s, dev, prod
+	}
+	s, dev, prod := run("config.yaml", "dev,local", "missing")
+	if s.Addr != "localhost" || s.Port != 8080 || s.Mode != "local" || !dev || prod {
+		t.Fatalf("unexpected profile config %+v, dev %v, prod %v", s, dev, prod)
+	}
+	if s, _, _ = run("config.yaml", "local", "dev"); s.Mode != "dev" {
+		t.Fatalf("later profile does not win %+v", s)
+	}
+	t.Setenv(ProfilesEnv, "env")
+	if s, dev, _ = run("config.yaml"); s.Mode != "env" || s.Port != 80 || dev {
+		t.Fatalf("unexpected env profile config %+v, dev %v", s, dev)
+	}
+	t.Setenv(ProfilesEnv, "")
+	if s, dev, _ = run("app.yaml"); s.Mode != "conf" || !dev {
+		t.Fatalf("unexpected config profile config %+v, dev %v", s, dev)
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	plain, reloadable := &TestCountingConfigLoader{}, &TestReloadableConfigLoader{}
+	conf := NewConfContext(plain, reloadable)
+	if err := conf.initConf(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conf.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if plain.loads.Load() != 1 || reloadable.loads.Load() != 2 {
+		t.Fatalf("unexpected loads %d, %d", plain.loads.Load(), reloadable.loads.Load())
+	}
+}
+
+func TestFileConfigReloadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"config.yaml":     "server:\n  mode: base\n",
+		"config-dev.yaml": "server:\n  mode: dev\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mode := func(conf Conf) any {
+		server, _ := conf["server"].(map[string]any)
+		return server["mode"]
+	}
+	conf := Conf{}
+	f := NewFileConfigLoader(filepath.Join(dir, "config.yaml"))
+	f.profiles = []string{"dev"}
+	if err := f.Reload(&ConfigUpdater{target: &conf}); err != nil {
+		t.Fatal(err)
+	}
+	if mode(conf) != "dev" {
+		t.Fatalf("profiles dropped by reload without base config: %v", conf)
+	}
+	conf = Conf{}
+	if err := f.Reload(&ConfigUpdater{target: &conf}); err != nil {
+		t.Fatal(err)
+	}
+	if mode(conf) != "dev" {
+		t.Fatalf("profiles dropped by reload: %v", conf)
+	}
+}
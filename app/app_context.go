@@ -3,7 +3,10 @@ package app
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ExitStatus struct {
@@ -14,19 +17,39 @@ type AppContext struct {
 	rootCtx context.Context
 	// id - meta
 	components map[string]*ComponentMeta[Component]
+	// id - meta, including components not initialized
+	registered map[string]*ComponentMeta[Component]
 	// type - meta
 	singletonComponents map[string]*ComponentMeta[Component]
 	componentMetas      map[Component]*ComponentMeta[Component]
-	param               map[string]any
-	conf                *ConfContext
-	initSequence        []string
-	MainLog             Logger
-	exitNotifyCh        chan<- string
-	exitFinishedCh      chan<- struct{}
-	exitErrCh           chan<- error
-	exitErrs            []error
-	exitActionWg        sync.WaitGroup
-	exited              bool
+	// id - ids of dependencies
+	dependencies   map[string][]string
+	healthChecks   map[string]*healthCheck
+	params         *Params
+	conf           *ConfContext
+	initSequence   []string
+	MainLog        Logger
+	exitNotifyCh   chan<- string
+	exitFinishedCh chan<- struct{}
+	exitErrCh      chan<- error
+	exitErrs       []error
+	exitActionWg   sync.WaitGroup
+	exited         atomic.Bool
+	concurrentExit bool
+	abortRun       context.CancelFunc
+	profiler       *profiler
+	exiting        atomic.Bool
+	exitingCh      chan struct{}
+	initializer    *ComponentInitializer
+	runComponent   func(meta *ComponentMeta[Component])
+	// id - instance of prototype components
+	instances map[string]*ComponentMeta[Component]
+	typeIndex *typeIndex
+	// id - closed when Run of the component returned
+	running         map[string]chan struct{}
+	shutdownTimeout time.Duration
+	registerLock    sync.Mutex
+	lock            sync.RWMutex
 }
 
 func newAppContext(rootCtx context.Context, conf *ConfContext, exitNotifyCh chan<- string, exitFinishedCh chan<- struct{}, logger Logger, param map[string]any) *AppContext {
@@ -34,14 +57,21 @@ func newAppContext(rootCtx context.Context, conf *ConfContext, exitNotifyCh chan
 	ac := &AppContext{
 		rootCtx:             rootCtx,
 		components:          map[string]*ComponentMeta[Component]{},
+		registered:          map[string]*ComponentMeta[Component]{},
 		singletonComponents: map[string]*ComponentMeta[Component]{},
 		componentMetas:      map[Component]*ComponentMeta[Component]{},
-		param:               param,
+		dependencies:        map[string][]string{},
+		healthChecks:        map[string]*healthCheck{},
+		params:              newParams(param),
 		conf:                conf,
 		MainLog:             logger,
 		exitNotifyCh:        exitNotifyCh,
 		exitFinishedCh:      exitFinishedCh,
 		exitErrCh:           exitErrCh,
+		exitingCh:           make(chan struct{}),
+		running:             map[string]chan struct{}{},
+		instances:           map[string]*ComponentMeta[Component]{},
+		typeIndex:           newTypeIndex(nil),
 	}
 	go func() {
 		for err := range exitErrCh {
@@ -54,7 +84,21 @@ func newAppContext(rootCtx context.Context, conf *ConfContext, exitNotifyCh chan
 	return ac
 }
 
-func (a *AppContext) addComponent(meta *ComponentMeta[Component]) {
+func (a *AppContext) register(meta *ComponentMeta[Component]) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if _, ok := a.registered[meta.ID()]; !ok {
+		a.typeIndex.add(string(meta.componentType))
+	}
+	a.registered[meta.ID()] = meta
+}
+
+func (a *AppContext) addComponent(meta *ComponentMeta[Component], dependencies []*ComponentMeta[Component]) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for _, d := range dependencies {
+		a.dependencies[meta.ID()] = append(a.dependencies[meta.ID()], d.ID())
+	}
 	a.components[meta.ID()] = meta
 	if meta.IsSingleton() {
 		a.singletonComponents[string(meta.componentType)] = meta
@@ -62,16 +106,77 @@ func (a *AppContext) addComponent(meta *ComponentMeta[Component]) {
 	a.componentMetas[meta.component] = meta
 }
 
+// addInstance tracks instance of prototype, it depends on what prototype depends on and owner depends on it
+func (a *AppContext) addInstance(owner, prototype, instance *ComponentMeta[Component]) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.instances[instance.ID()] = instance
+	a.componentMetas[instance.component] = instance
+	a.dependencies[instance.ID()] = append(a.dependencies[instance.ID()], a.dependencies[prototype.ID()]...)
+	if owner != nil {
+		a.dependencies[owner.ID()] = append(a.dependencies[owner.ID()], instance.ID())
+	}
+	a.initSequence = append(a.initSequence, instance.ID())
+}
+
+// ReleaseComponent closes an instance of prototype fetched by GetComponent and the instances injected into it,
+// instances not released are closed at shutdown
+func (a *AppContext) ReleaseComponent(c Component) error {
+	a.lock.Lock()
+	meta := a.componentMetas[c]
+	if meta == nil || !meta.fetched || a.instances[meta.ID()] != meta {
+		a.lock.Unlock()
+		return fmt.Errorf("%w: %T", ErrNotFetchedInstance, c)
+	}
+	released := []*ComponentMeta[Component]{meta}
+	for i := 0; i < len(released); i++ {
+		for _, id := range a.dependencies[released[i].ID()] {
+			if instance, ok := a.instances[id]; ok {
+				released = append(released, instance)
+			}
+		}
+	}
+	ids := map[string]struct{}{}
+	for _, m := range released {
+		ids[m.ID()] = struct{}{}
+		delete(a.instances, m.ID())
+		delete(a.componentMetas, m.component)
+		delete(a.dependencies, m.ID())
+	}
+	a.initSequence = slices.DeleteFunc(a.initSequence, func(id string) bool {
+		_, ok := ids[id]
+		return ok
+	})
+	a.lock.Unlock()
+	var errs []error
+	for _, m := range released {
+		if err := m.close(); err != nil {
+			errs = append(errs, componentError(m.ID(), err))
+		}
+	}
+	return joinErrors(errs)
+}
+
 func (a *AppContext) GetParam(name string) (d any, ok bool) {
-	d, ok = a.param[name]
-	return
+	return a.params.Get(name)
+}
+
+func (a *AppContext) SetParam(name string, value any) {
+	a.params.Set(name, value)
+}
+
+// SubscribeParam listens changes of the param made by SetParam, call the returned function to unsubscribe
+func (a *AppContext) SubscribeParam(name string, listener ParamListener) func() {
+	return a.params.Subscribe(name, listener)
 }
 
-func (a *AppContext) SetParam(name string, value string) {
-	a.param[name] = value
+func (a *AppContext) Params() *Params {
+	return a.params
 }
 
 func (a *AppContext) Meta(c Component) *ComponentMeta[Component] {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
 	return a.componentMetas[c]
 }
 
@@ -80,22 +185,39 @@ func (a *AppContext) GetComponentMeta(componentType ComponentType, name string)
 	return a.GetComponentMetaById(id)
 }
 func (a *AppContext) GetComponentMetaById(id string) *ComponentMeta[Component] {
+	meta, _ := a.GetComponentMetaByIdE(id)
+	return meta
+}
+
+// resolveID returns the registered id of id which may use short type name, a.lock must be held
+func (a *AppContext) resolveID(id string) (string, error) {
+	if _, ok := a.registered[id]; ok {
+		return id, nil
+	}
+	return a.typeIndex.resolveID(id)
+}
+
+// GetComponentMetaByIdE is like GetComponentMetaById, but returns the error why the component is unavailable
+func (a *AppContext) GetComponentMetaByIdE(id string) (*ComponentMeta[Component], error) {
+	a.lock.RLock()
+	id, err := a.resolveID(id)
+	if err != nil {
+		a.lock.RUnlock()
+		return nil, err
+	}
 	meta, ok := a.components[id]
+	registered := a.registered[id]
+	a.lock.RUnlock()
 	if !ok {
-		return nil
-	}
-	if meta.IsLazyInit() {
-		if !meta.IsLazyInitialized() {
-			err := meta.lazyinit(a, a.conf)
-			if err != nil {
-				a.MainLog.Warnf("fail to lazy init component meta: %v", err)
-			}
-			a.initSequence = append(a.initSequence, meta.ID())
+		if registered != nil && registered.LastError() != nil {
+			return nil, componentError(id, registered.LastError())
 		}
-	} else if !meta.IsInitialized() {
-		return nil
+		return nil, ErrComponentNotFound
 	}
-	return meta
+	if meta.IsPrototype() {
+		return a.initializer.newInstance(nil, meta)
+	}
+	return meta, a.ensureInitialized(meta)
 }
 func (a *AppContext) GetComponent(componentType ComponentType, name string) Component {
 	id := getComponentID(componentType, name)
@@ -103,51 +225,152 @@ func (a *AppContext) GetComponent(componentType ComponentType, name string) Comp
 }
 
 func (a *AppContext) GetComponentById(id string) Component {
-	meta := a.GetComponentMetaById(id)
-	return meta.component
+	c, _ := a.GetComponentByIdE(id)
+	return c
+}
+
+// GetComponentByIdE is like GetComponentById, but returns the error why the component is unavailable
+func (a *AppContext) GetComponentByIdE(id string) (Component, error) {
+	meta, err := a.GetComponentMetaByIdE(id)
+	if err != nil {
+		return nil, err
+	}
+	return meta.component, nil
 }
 
 func (a *AppContext) GetSingletonComponent(componentType string) Component {
+	c, _ := a.GetSingletonComponentE(componentType)
+	return c
+}
+
+// GetSingletonComponentE is like GetSingletonComponent, but returns the error why the component is unavailable
+func (a *AppContext) GetSingletonComponentE(componentType string) (Component, error) {
+	a.lock.RLock()
+	if _, ok := a.singletonComponents[componentType]; !ok {
+		var err error
+		if componentType, err = a.typeIndex.resolveType(componentType); err != nil {
+			a.lock.RUnlock()
+			return nil, err
+		}
+	}
 	meta, ok := a.singletonComponents[componentType]
+	a.lock.RUnlock()
 	if !ok {
+		return nil, ErrComponentNotFound
+	}
+	if meta.IsPrototype() {
+		instance, err := a.initializer.newInstance(nil, meta)
+		if err != nil {
+			return nil, err
+		}
+		return instance.component, nil
+	}
+	if err := a.ensureInitialized(meta); err != nil {
+		return nil, err
+	}
+	return meta.component, nil
+}
+
+// ensureInitialized lazy inits the component and its lazy dependencies first,
+// so that init sequence keeps dependencies before dependents
+func (a *AppContext) ensureInitialized(meta *ComponentMeta[Component]) error {
+	if !meta.IsLazyInit() {
+		if meta.IsInitialized() {
+			return nil
+		}
+		if err := meta.LastError(); err != nil {
+			return componentError(meta.ID(), err)
+		}
+		return ErrComponentNotFound
+	}
+	if meta.IsLazyInitialized() {
 		return nil
 	}
-	if !meta.IsInitialized() {
-		if meta.IsLazyInit() {
-			err := meta.lazyinit(a, a.conf)
-			if err != nil {
-				a.MainLog.Warnf("fail to lazy init component meta: %v", err)
+	a.lock.RLock()
+	var deps []*ComponentMeta[Component]
+	for _, id := range a.dependencies[meta.ID()] {
+		if d, ok := a.components[id]; ok {
+			deps = append(deps, d)
+		}
+	}
+	a.lock.RUnlock()
+	for _, d := range deps {
+		if err := a.ensureInitialized(d); err != nil && !d.IsIgnoreError() {
+			return componentError(meta.ID(), fmt.Errorf("dependency %s unavailable: %w", d.ID(), err))
+		}
+	}
+	end := a.profiler.span(profileInit, meta.ID())
+	done, err := meta.lazyinit(a.rootCtx, a, meta.confOf(a.conf))
+	end()
+	if err != nil {
+		if done {
+			a.MainLog.Warnf("fail to lazy init component %s: %v", meta.ID(), err)
+		}
+		return componentError(meta.ID(), err)
+	}
+	if done {
+		a.addHealthCheck(meta)
+		a.lock.Lock()
+		a.initSequence = append(a.initSequence, meta.ID())
+		a.lock.Unlock()
+	}
+	return nil
+}
+
+// closeOrder returns initialized components in the order they should be closed, dependents always close before their dependencies
+func (a *AppContext) closeOrder() []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	initialized := map[string]struct{}{}
+	for _, id := range a.initSequence {
+		initialized[id] = struct{}{}
+	}
+	dependents := map[string][]string{}
+	for id, deps := range a.dependencies {
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+	var order []string
+	visited := map[string]struct{}{}
+	var visit func(id string)
+	visit = func(id string) {
+		if _, ok := visited[id]; ok {
+			return
+		}
+		visited[id] = struct{}{}
+		for _, dependent := range dependents[id] {
+			if _, ok := initialized[dependent]; ok {
+				visit(dependent)
 			}
-			a.initSequence = append(a.initSequence, meta.ID())
-		} else {
-			return nil
 		}
+		order = append(order, id)
+	}
+	for i := len(a.initSequence) - 1; i >= 0; i-- {
+		visit(a.initSequence[i])
 	}
-	return meta.component
+	return order
 }
+
 func (a *AppContext) Exit(msg ...string) {
-	if a.exited {
+	if a.exited.Load() || !a.exiting.CompareAndSwap(false, true) {
 		return
 	}
-	for _, c := range a.components {
-		if rc, ok := c.component.(RunnableComponent); ok {
-			a.exitActionWg.Add(1)
-			go func() {
-				var rErr error
-				defer func() {
-					if err := recover(); err != nil {
-						rErr = fmt.Errorf("component[%s] panic when exit:%v", c.ID(), err)
-					}
-					if rErr != nil {
-						a.exitErrCh <- rErr
-					} else {
-						a.exitErrCh <- nil
-					}
-				}()
-				rErr = rc.OnExit()
-			}()
-		}
+	close(a.exitingCh)
+	levels := a.exitLevels()
+	for _, level := range levels {
+		a.exitActionWg.Add(len(level))
 	}
+	go func() {
+		for _, level := range levels {
+			var wg sync.WaitGroup
+			wg.Add(len(level))
+			for _, c := range level {
+				go a.exitOne(c, &wg)
+			}
+			wg.Wait()
+		}
+	}()
 	go func() {
 		a.exitActionWg.Wait()
 		a.exitFinishedCh <- struct{}{}
@@ -159,6 +382,101 @@ func (a *AppContext) Exit(msg ...string) {
 	}
 }
 
+func (a *AppContext) exitOne(c *ComponentMeta[Component], wg *sync.WaitGroup) {
+	var rErr error
+	defer func() {
+		if err := recover(); err != nil {
+			rErr = fmt.Errorf("component[%s] panic when exit:%v", c.ID(), err)
+		}
+		a.exitErrCh <- rErr
+		a.waitStopped(c)
+		wg.Done()
+	}()
+	if c.State() == StateRunning {
+		c.setState(StateStopping, nil)
+	}
+	end := a.profiler.span(profileOnExit, c.ID())
+	rErr = c.fail(c.component.(RunnableComponent).OnExit())
+	end()
+}
+
+// waitStopped waits until Run of the component returns, at most shutdownTimeout when it is set
+func (a *AppContext) waitStopped(meta *ComponentMeta[Component]) {
+	a.lock.RLock()
+	done, running := a.running[meta.ID()]
+	a.lock.RUnlock()
+	if !running {
+		return
+	}
+	if a.shutdownTimeout == 0 {
+		<-done
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(a.shutdownTimeout):
+		a.MainLog.Warnf("component %s does not stop in %v", meta.ID(), a.shutdownTimeout)
+	}
+}
+
+// exitLevels groups runnable components so that a component exits only after Run of all components depending on it returned,
+// all runnable components are in one level when concurrent exit is enabled
+func (a *AppContext) exitLevels() [][]*ComponentMeta[Component] {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	var levels [][]*ComponentMeta[Component]
+	if a.concurrentExit {
+		var level []*ComponentMeta[Component]
+		for _, c := range a.components {
+			if _, ok := c.component.(RunnableComponent); ok {
+				level = append(level, c)
+			}
+		}
+		return append(levels, level)
+	}
+	dependents := map[string][]string{}
+	for id, deps := range a.dependencies {
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+	depth := map[string]int{}
+	var visit func(id string) int
+	visit = func(id string) int {
+		if d, ok := depth[id]; ok {
+			return d
+		}
+		level := 0
+		for _, dependent := range dependents[id] {
+			level = max(level, visit(dependent)+1)
+		}
+		depth[id] = level
+		return level
+	}
+	for id, c := range a.components {
+		if _, ok := c.component.(RunnableComponent); !ok {
+			continue
+		}
+		level := visit(id)
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], c)
+	}
+	return levels
+}
+
+// abort stops waiting for runnable components without calling OnExit
+func (a *AppContext) abort() {
+	if a.abortRun != nil {
+		a.abortRun()
+	}
+}
+
+func (a *AppContext) isExiting() bool {
+	return a.exiting.Load()
+}
+
 func (a *AppContext) setExited() {
-	a.exited = true
+	a.exited.Store(true)
 }
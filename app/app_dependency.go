@@ -14,6 +14,10 @@ type fieldInfo struct {
 	IsDependAll bool
 	DependType  ComponentType
 	DependIds   []string
+	// DependIface is set when field depends on every component implementing the interface,
+	// DependNames selects the implementations by component name
+	DependIface reflect.Type
+	DependNames []string
 	Required    bool
 }
 
@@ -31,7 +35,7 @@ func resolveDependencies(component Component) (typeName string, types []Componen
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
-	typeName = t.Name()
+	typeName = qualifiedTypeName(t)
 	if t.Kind() == reflect.Struct {
 		for i := 0; i < t.NumField(); i++ {
 			field := t.Field(i)
@@ -76,14 +80,19 @@ func resolveDependencies(component Component) (typeName string, types []Componen
 				case reflect.Struct:
 					err = errors.New("ekit only support pointer receiver for component field: " + typeName + "." + field.Name)
 					return
-				case reflect.Ptr:
+				case reflect.Ptr, reflect.Interface:
 					if valueCount > 1 {
 						err = errors.New("component only support nomore than 1 candidates" + field.Name)
 						return
 					}
-					fieldType = fieldType.Elem()
+					if fieldKind == reflect.Ptr {
+						fieldType = fieldType.Elem()
+					}
 				case reflect.Slice:
 					fieldType = fieldType.Elem()
+					if fieldType.Kind() == reflect.Interface {
+						break
+					}
 					if fieldType.Kind() != reflect.Ptr {
 						err = errors.New("ekit only support pointer receiver for component slice field: " + typeName + "." + field.Name)
 						return
@@ -96,6 +105,9 @@ func resolveDependencies(component Component) (typeName string, types []Componen
 						return
 					}
 					fieldType = fieldType.Elem()
+					if fieldType.Kind() == reflect.Interface {
+						break
+					}
 					if fieldType.Kind() != reflect.Ptr {
 						err = errors.New("ekit only support pointer receiver value for component map field: " + typeName + "." + field.Name)
 						return
@@ -105,14 +117,20 @@ func resolveDependencies(component Component) (typeName string, types []Componen
 					err = errors.New("unsupported Field kind:" + fieldKind.String())
 					return
 				}
-				if valueCount == 0 {
-					types = append(types, ComponentType(fieldType.Name()))
+				if fieldType.Kind() == reflect.Interface {
+					fi.DependIface = fieldType
+					fi.IsDependAll = valueCount == 0
+					for _, value := range tag.Values {
+						fi.DependNames = append(fi.DependNames, strings.ToLower(value))
+					}
+				} else if valueCount == 0 {
+					types = append(types, ComponentType(qualifiedTypeName(fieldType)))
 					fi.IsDependAll = true
-					fi.DependType = ComponentType(fieldType.Name())
+					fi.DependType = ComponentType(qualifiedTypeName(fieldType))
 				} else {
 					fi.DependIds = []string{}
 					for _, value := range tag.Values {
-						id := getComponentID(ComponentType(fieldType.Name()), value)
+						id := getComponentID(ComponentType(qualifiedTypeName(fieldType)), value)
 						instances = append(instances, id)
 						fi.DependIds = append(fi.DependIds, id)
 					}
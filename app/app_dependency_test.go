@@ -0,0 +1,67 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+type TestStorage interface {
+	Component
+	Kind() string
+}
+
+type TestMemStorage struct {
+	SimpleComponent
+}
+
+func (s *TestMemStorage) Kind() string {
+	return "mem"
+}
+
+type TestDiskStorage struct {
+	SimpleComponent
+}
+
+func (s *TestDiskStorage) Kind() string {
+	return "disk"
+}
+
+type TestStorageUser struct {
+	SimpleComponent
+	Store  TestStorage            `ekit:"component"`
+	Disk   TestStorage            `ekit:"component:disk"`
+	All    []TestStorage          `ekit:"component"`
+	ByName map[string]TestStorage `ekit:"component"`
+}
+
+func TestInterfaceInject(t *testing.T) {
+	app := App("demo")
+	mem, disk := &TestMemStorage{}, &TestDiskStorage{}
+	app.WithNamedComponent("mem", mem, WithPrimary[Component])
+	app.WithNamedComponent("disk", disk)
+	user := &TestStorageUser{}
+	app.WithComponent(user)
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range app.Graph().Edges {
+		if e.From == testID(user) && e.Source != EdgeSourceInterface {
+			t.Fatalf("unexpected edge source %+v", e)
+		}
+	}
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if user.Store != mem || user.Disk != disk || len(user.All) != 2 || user.ByName["disk"] != disk || user.ByName["mem"] != mem {
+		t.Fatalf("unexpected injection %+v", user)
+	}
+
+	app = App("demo")
+	app.WithNamedComponent("mem", &TestMemStorage{})
+	app.WithNamedComponent("disk", &TestDiskStorage{})
+	app.WithComponent(&TestStorageUser{})
+	if err := app.Validate(); !errors.Is(err, ErrAmbiguousDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"errors"
+)
+
+var (
+	ErrCircularDependency  = errors.New("circular dependencies")
+	ErrMissingDependency   = errors.New("missing dependency")
+	ErrAmbiguousDependency = errors.New("found more than 1 candidates")
+	ErrDuplicatePrimary    = errors.New("duplicated primary component")
+	ErrDuplicateComponent  = errors.New("component duplicate")
+	// ErrAmbiguousComponentType means a short type name matches types in different packages
+	ErrAmbiguousComponentType = errors.New("ambiguous component type")
+	ErrComponentInUse         = errors.New("component is depended by other components")
+	ErrAppNotRunning          = errors.New("app is not running")
+	ErrDuplicateModule        = errors.New("module prefix duplicate")
+	ErrConditionNotMatched    = errors.New("condition not matched")
+	ErrNotFetchedInstance     = errors.New("component is not an instance fetched from prototype")
+)
+
+type Phase string
+
+const (
+	PhaseConfig Phase = "config"
+	PhaseLog    Phase = "log"
+	PhaseSetup  Phase = "setup"
+	PhaseInit   Phase = "init"
+	PhaseRun    Phase = "run"
+	PhaseClose  Phase = "close"
+)
+
+// StartError tells which phase and which component made app failed
+type StartError struct {
+	Phase       Phase
+	ComponentID string
+	Err         error
+	exitCode    int
+}
+
+func newStartError(phase Phase, componentID string, err error) *StartError {
+	return &StartError{
+		Phase:       phase,
+		ComponentID: componentID,
+		Err:         err,
+	}
+}
+
+// componentError attributes err to the component, err already attributed is returned as it is
+func componentError(componentID string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var se *StartError
+	if errors.As(err, &se) {
+		return err
+	}
+	return newStartError(PhaseInit, componentID, err)
+}
+
+func (e *StartError) Error() string {
+	if e.ComponentID != "" {
+		return "[" + e.ComponentID + "] " + e.Err.Error()
+	}
+	return e.Err.Error()
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of Start: config 1, log 2, app context 3, setup or init 4, run 5, close 6
+func (e *StartError) ExitCode() int {
+	if e.exitCode != 0 {
+		return e.exitCode
+	}
+	switch e.Phase {
+	case PhaseConfig:
+		return 1
+	case PhaseLog:
+		return 2
+	case PhaseSetup, PhaseInit:
+		return 4
+	case PhaseRun:
+		return 5
+	case PhaseClose:
+		return 6
+	}
+	return 1
+}
+
+// asStartError attributes err to phase unless it is attributed already
+func asStartError(phase Phase, err error) *StartError {
+	if se, ok := err.(*StartError); ok && se.Phase == phase {
+		return se
+	}
+	return newStartError(phase, "", err)
+}
+
+// joinErrors keeps the attribution when only one error is not nil
+func joinErrors(errs []error) error {
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) == 1 {
+		return failed[0]
+	}
+	return errors.Join(failed...)
+}
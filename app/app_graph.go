@@ -0,0 +1,197 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type EdgeSource string
+
+const (
+	// EdgeSourceTag means the dependency is declared by ekit tag on field
+	EdgeSourceTag EdgeSource = "tag"
+	// EdgeSourceOption means the dependency is declared by WithDependencyTypes or WithDependencies
+	EdgeSourceOption EdgeSource = "option"
+	// EdgeSourceDependencies means the dependency is declared by EkitDependencies
+	EdgeSourceDependencies EdgeSource = "dependencies"
+	// EdgeSourceProvider means the dependency is a parameter of provider
+	EdgeSourceProvider EdgeSource = "provider"
+	// EdgeSourceInterface means the dependency implements the interface of a field or provider parameter
+	EdgeSourceInterface EdgeSource = "interface"
+)
+
+type GraphNode struct {
+	ID        string        `json:"id"`
+	Type      ComponentType `json:"type"`
+	Name      string        `json:"name"`
+	Lazy      bool          `json:"lazy"`
+	Singleton bool          `json:"singleton"`
+	Primary   bool          `json:"primary"`
+	Prototype bool          `json:"prototype"`
+}
+
+// GraphEdge points from the component to its dependency
+type GraphEdge struct {
+	From   string     `json:"from"`
+	To     string     `json:"to"`
+	Source EdgeSource `json:"source"`
+}
+
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// Graph returns the dependency graph of registered components, it works both before and after Start
+func (r *RootComponent) Graph() *Graph {
+	var components map[string]*ComponentMeta[Component]
+	if r.componentHolder != nil {
+		components = r.allComponents()
+	} else if r.app != nil {
+		r.app.lock.RLock()
+		components = make(map[string]*ComponentMeta[Component], len(r.app.registered))
+		for id, meta := range r.app.registered {
+			components[id] = meta
+		}
+		r.app.lock.RUnlock()
+	}
+	m, _, _ := groupComponents(components)
+	ci := &ComponentInitializer{
+		componentGraph:       components,
+		componentGroupByType: m,
+		sortedGraphIds:       sortedIds(components),
+		typeIndex:            newTypeIndex(components),
+	}
+	return ci.graph()
+}
+
+func (ci *ComponentInitializer) graph() *Graph {
+	g := &Graph{
+		Nodes: []GraphNode{},
+		Edges: []GraphEdge{},
+	}
+	for _, id := range ci.sortedGraphIds {
+		meta := ci.componentGraph[id]
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:        meta.ID(),
+			Type:      meta.Type(),
+			Name:      meta.Name(),
+			Lazy:      meta.IsLazyInit(),
+			Singleton: meta.IsSingleton(),
+			Primary:   meta.IsPrimary(),
+			Prototype: meta.IsPrototype(),
+		})
+		added := map[string]struct{}{}
+		addEdge := func(to *ComponentMeta[Component], source EdgeSource) {
+			if _, ok := added[to.ID()]; ok {
+				return
+			}
+			added[to.ID()] = struct{}{}
+			g.Edges = append(g.Edges, GraphEdge{From: meta.ID(), To: to.ID(), Source: source})
+		}
+		for _, t := range meta.DependencyTypes() {
+			rt, _ := ci.resolveType(t)
+			for _, dep := range ci.componentGroupByType[rt] {
+				addEdge(dep, edgeSource(meta, t))
+			}
+		}
+		for _, instance := range meta.Dependencies() {
+			if dep := ci.getMetaById(instance); dep != nil {
+				addEdge(dep, edgeSource(meta, instance))
+			}
+		}
+		for _, dep := range ci.interfaceDependencies(meta) {
+			addEdge(dep, EdgeSourceInterface)
+		}
+	}
+	return g
+}
+
+func edgeSource(meta *ComponentMeta[Component], key string) EdgeSource {
+	if meta.provider != nil && !meta.IsAdditionalDepends(key) {
+		return EdgeSourceProvider
+	}
+	if meta.IsAdditionalDepends(key) {
+		return EdgeSourceOption
+	}
+	if meta.IsExtendDepends(key) {
+		return EdgeSourceDependencies
+	}
+	return EdgeSourceTag
+}
+
+func (n GraphNode) flags() []string {
+	var flags []string
+	if n.Lazy {
+		flags = append(flags, "lazy")
+	}
+	if n.Singleton {
+		flags = append(flags, "singleton")
+	}
+	if n.Primary {
+		flags = append(flags, "primary")
+	}
+	if n.Prototype {
+		flags = append(flags, "prototype")
+	}
+	return flags
+}
+
+// DOT renders graph in Graphviz DOT format, lazy components are dashed, primary components are bold
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph ekit {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		label := n.ID
+		if flags := n.flags(); len(flags) > 0 {
+			label += "\\n" + strings.Join(flags, ", ")
+		}
+		var styles []string
+		if n.Lazy {
+			styles = append(styles, "dashed")
+		}
+		if n.Primary {
+			styles = append(styles, "bold")
+		}
+		attrs := fmt.Sprintf("label=%q", label)
+		if len(styles) > 0 {
+			attrs += fmt.Sprintf(" style=%q", strings.Join(styles, ","))
+		}
+		if n.Singleton {
+			attrs += " peripheries=2"
+		}
+		sb.WriteString(fmt.Sprintf("  %q [%s];\n", n.ID, attrs))
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %q -> %q [label=%q];\n", e.From, e.To, e.Source))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid renders graph as a mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	nodeIds := map[string]string{}
+	for i, n := range g.Nodes {
+		nodeId := fmt.Sprintf("n%d", i)
+		nodeIds[n.ID] = nodeId
+		label := n.ID
+		if flags := n.flags(); len(flags) > 0 {
+			label += "<br/>" + strings.Join(flags, ", ")
+		}
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", nodeId, strings.ReplaceAll(label, "\"", "#quot;")))
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %s -->|%s| %s\n", nodeIds[e.From], e.Source, nodeIds[e.To]))
+	}
+	return sb.String()
+}
+
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
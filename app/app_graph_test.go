@@ -0,0 +1,45 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+type TestGraphExtend struct {
+	SimpleComponent
+}
+
+func (g *TestGraphExtend) EkitDependencies() ([]ComponentType, []string) {
+	return []ComponentType{"TestValidateStore"}, nil
+}
+
+func TestGraph(t *testing.T) {
+	app := App("demo")
+	app.WithComponent(&TestValidateStore{}, WithPrimary[Component])
+	app.WithComponent(&TestValidateService{})
+	app.WithComponent(&TestGraphExtend{}, WithLazyInit[Component])
+	app.WithComponentMeta("opt", NewComponentMeta[Component]("GraphOption", &SimpleComponent{}, WithDependencyTypes[Component]("TestValidateService")))
+	g := app.Graph()
+	if len(g.Nodes) != 4 || len(g.Edges) != 3 {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+	sources := map[string]EdgeSource{}
+	for _, e := range g.Edges {
+		sources[e.From] = e.Source
+	}
+	if sources[testID(&TestValidateService{})] != EdgeSourceTag ||
+		sources[testID(&TestGraphExtend{})] != EdgeSourceDependencies ||
+		sources["GraphOption:opt"] != EdgeSourceOption {
+		t.Fatalf("unexpected edge sources %v", sources)
+	}
+	if dot := g.DOT(); !strings.Contains(dot, `"`+testID(&TestValidateService{})+`" -> "`+testID(&TestValidateStore{})+`" [label="tag"]`) {
+		t.Fatalf("unexpected dot %s", dot)
+	}
+	if mermaid := g.Mermaid(); !strings.Contains(mermaid, "lazy") || !strings.Contains(mermaid, "-->|option|") {
+		t.Fatalf("unexpected mermaid %s", mermaid)
+	}
+	data, err := g.JSON()
+	if err != nil || !strings.Contains(string(data), `"primary": true`) {
+		t.Fatalf("unexpected json %s, %v", data, err)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const DefaultHealthCheckTimeout = 3 * time.Second
+
+type HealthChecker interface {
+	Health(ctx context.Context) HealthStatus
+}
+
+type HealthStatus struct {
+	Live    bool   `json:"live"`
+	Ready   bool   `json:"ready"`
+	Message string `json:"message,omitempty"`
+}
+
+type ComponentHealth struct {
+	HealthStatus
+	ID        string    `json:"id"`
+	Critical  bool      `json:"critical"`
+	Cached    bool      `json:"cached"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// HealthReport is live or ready only if all critical components are live or ready
+type HealthReport struct {
+	Live       bool                       `json:"live"`
+	Ready      bool                       `json:"ready"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+func WithHealthCheckTimeout[T Component](d time.Duration) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.healthTimeout = d
+	}
+}
+
+func WithHealthCacheTTL[T Component](d time.Duration) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.healthCacheTTL = d
+	}
+}
+
+// WithNonCritical reports health of component but ignores it in the aggregate status
+func WithNonCritical[T Component](meta *ComponentMeta[T]) {
+	meta.nonCritical = true
+}
+
+type healthCheck struct {
+	id       string
+	checker  HealthChecker
+	timeout  time.Duration
+	cacheTTL time.Duration
+	critical bool
+	lock     sync.Mutex
+	last     ComponentHealth
+}
+
+func newHealthCheck(meta *ComponentMeta[Component]) (*healthCheck, bool) {
+	checker, ok := meta.component.(HealthChecker)
+	if !ok {
+		return nil, false
+	}
+	timeout := meta.healthTimeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+	return &healthCheck{
+		id:       meta.ID(),
+		checker:  checker,
+		timeout:  timeout,
+		cacheTTL: meta.healthCacheTTL,
+		critical: !meta.nonCritical,
+	}, true
+}
+
+func (h *healthCheck) check(ctx context.Context) ComponentHealth {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.cacheTTL > 0 && !h.last.CheckedAt.IsZero() && time.Since(h.last.CheckedAt) < h.cacheTTL {
+		result := h.last
+		result.Cached = true
+		return result
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	done := make(chan HealthStatus, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- HealthStatus{Message: fmt.Sprintf("panic at health check:%v", err)}
+			}
+		}()
+		done <- h.checker.Health(ctx)
+	}()
+	var status HealthStatus
+	select {
+	case status = <-done:
+	case <-ctx.Done():
+		status = HealthStatus{Message: "health check failed: " + ctx.Err().Error()}
+	}
+	h.last = ComponentHealth{
+		HealthStatus: status,
+		ID:           h.id,
+		Critical:     h.critical,
+		CheckedAt:    time.Now(),
+	}
+	return h.last
+}
+
+func (a *AppContext) addHealthCheck(meta *ComponentMeta[Component]) {
+	hc, ok := newHealthCheck(meta)
+	if !ok {
+		return
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.healthChecks[meta.ID()] = hc
+}
+
+// skippedHealth reports a component skipped by ignore error as live but not ready
+type skippedHealth struct {
+	err error
+}
+
+func (s skippedHealth) Health(ctx context.Context) HealthStatus {
+	return HealthStatus{Live: true, Message: "init failed, skipped: " + s.err.Error()}
+}
+
+// addSkippedHealthCheck reports the component failed with ignore error in Health, so degraded mode is visible
+func (a *AppContext) addSkippedHealthCheck(meta *ComponentMeta[Component], err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.healthChecks[meta.ID()] = &healthCheck{
+		id:       meta.ID(),
+		checker:  skippedHealth{err: err},
+		timeout:  DefaultHealthCheckTimeout,
+		critical: !meta.nonCritical,
+	}
+}
+
+// Health runs health checks of all initialized components concurrently
+func (a *AppContext) Health(ctx context.Context) HealthReport {
+	a.lock.RLock()
+	checks := make([]*healthCheck, 0, len(a.healthChecks))
+	for _, hc := range a.healthChecks {
+		checks = append(checks, hc)
+	}
+	a.lock.RUnlock()
+	report := HealthReport{
+		Live:       true,
+		Ready:      true,
+		Components: map[string]ComponentHealth{},
+	}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for _, hc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := hc.check(ctx)
+			lock.Lock()
+			defer lock.Unlock()
+			report.Components[result.ID] = result
+			if result.Critical {
+				report.Live = report.Live && result.Live
+				report.Ready = report.Ready && result.Ready
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
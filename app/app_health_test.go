@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type TestHealthComponent struct {
+	status HealthStatus
+	delay  time.Duration
+	checks atomic.Int32
+}
+
+func (h *TestHealthComponent) Init(app *AppContext, conf *ConfContext) error {
+	return nil
+}
+func (h *TestHealthComponent) Close() error {
+	return nil
+}
+func (h *TestHealthComponent) Health(ctx context.Context) HealthStatus {
+	h.checks.Add(1)
+	select {
+	case <-time.After(h.delay):
+		return h.status
+	case <-ctx.Done():
+		return HealthStatus{}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	app := App("demo")
+	var Healthy, Degraded, Slow ComponentType = "Healthy", "Degraded", "Slow"
+	healthy := &TestHealthComponent{status: HealthStatus{Live: true, Ready: true}}
+	degraded := &TestHealthComponent{status: HealthStatus{Live: true, Ready: false}}
+	slow := &TestHealthComponent{status: HealthStatus{Live: true, Ready: true}, delay: time.Hour}
+	app.WithComponentMeta("healthy", NewComponentMeta[Component](Healthy, healthy, WithHealthCacheTTL[Component](time.Hour)))
+	app.WithComponentMeta("degraded", NewComponentMeta[Component](Degraded, degraded, WithNonCritical[Component]))
+	app.WithComponentMeta("slow", NewComponentMeta[Component](Slow, slow, WithHealthCheckTimeout[Component](50*time.Millisecond)))
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	report := app.app.Health(context.Background())
+	if report.Live || report.Ready {
+		t.Fatalf("critical slow component should fail the report: %+v", report)
+	}
+	if len(report.Components) != 3 {
+		t.Fatalf("unexpected components in report: %+v", report.Components)
+	}
+	if h := report.Components[getComponentID(Degraded, "degraded")]; h.Critical || h.Ready {
+		t.Fatalf("unexpected degraded health: %+v", h)
+	}
+	report = app.app.Health(context.Background())
+	if h := report.Components[getComponentID(Healthy, "healthy")]; !h.Cached || !h.Ready || healthy.checks.Load() != 1 {
+		t.Fatalf("healthy result should be cached: %+v", h)
+	}
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"slices"
 	"strings"
+	"sync"
 )
 
 const MainLogger = "ekit"
@@ -15,10 +18,15 @@ func (r *RootComponent) initConf() error {
 		r.configLoaders = append(r.configLoaders, withEmptyConfigLoader())
 	}
 	confContext := NewConfContext(r.configLoaders...)
+	confContext.profiles = r.profiles
+	if len(confContext.profiles) == 0 {
+		confContext.profiles = parseProfiles(os.Getenv(ProfilesEnv))
+	}
 	err := confContext.initConf()
 	if err != nil {
 		return err
 	}
+	confContext.withDefaults(r.configDefaults...)
 	r.conf = confContext
 	return nil
 }
@@ -40,42 +48,76 @@ func (r *RootComponent) initLog() error {
 
 func (r *RootComponent) initAppContext() error {
 	app := newAppContext(r.rootCtx, r.conf, r.exitNotifyCh, r.exitFinishedCh, r.logger, r.param)
+	app.concurrentExit = r.concurrentExit
+	app.profiler = r.profiler
+	app.shutdownTimeout = r.gracefulShutdownTimeout
 	r.app = app
 	return nil
 }
-func (r *RootComponent) initComponents() error {
+func (r *RootComponent) initComponents(ctx context.Context) error {
+	if errs := r.checkDuplicates(); len(errs) > 0 {
+		return joinErrors(errs)
+	}
 	for _, c := range r.componentHolder {
-		if count := componentDupCheck[c.ID()]; count > 1 {
-			return errors.New("component duplicate: " + c.ID())
-		}
-		if c.IsSingleton() {
-			t := string(c.componentType)
-			if count := singletonComponentDupCheck[t]; count > 1 {
-				return errors.New("singleton component duplicate: " + t)
-			}
-		}
+		r.app.register(c)
 	}
 	ci, err := newComponentInitializer(r.componentHolder, r.app, r.conf, r.afterHandlers, r.beforeHandlers)
 	if err != nil {
 		return err
 	}
+	ci.parallelism = r.initParallelism
+	ci.profiler = r.profiler
+	ci.ctx = ctx
+	r.app.initializer = ci
 	initSeq, err := ci.InitializeAll()
 	if err != nil {
 		return err
 	}
-	r.app.initSequence = initSeq
+	// components initialized at runtime must not be limited by startup timeout
+	ci.ctx = r.app.rootCtx
+	r.app.lock.Lock()
+	r.app.initSequence = append(r.app.initSequence, initSeq...)
+	r.app.lock.Unlock()
 	r.componentHolder = nil
 	return nil
 }
 
+func (r *RootComponent) checkDuplicates() []error {
+	var errs []error
+	reported := map[string]struct{}{}
+	for _, id := range sortedIds(r.componentHolder) {
+		c := r.componentHolder[id]
+		if count := r.componentDupCheck[c.ID()]; count > 1 {
+			errs = append(errs, newStartError(PhaseInit, c.ID(), fmt.Errorf("%w: %s", ErrDuplicateComponent, c.ID())))
+		}
+		if c.IsSingleton() {
+			t := string(c.componentType)
+			if _, ok := reported[t]; ok {
+				continue
+			}
+			if count := r.singletonComponentDupCheck[t]; count > 1 {
+				reported[t] = struct{}{}
+				errs = append(errs, newStartError(PhaseInit, c.ID(), fmt.Errorf("singleton %w: %s", ErrDuplicateComponent, t)))
+			}
+		}
+	}
+	return errs
+}
+
 func (r *RootComponent) closeComponents() error {
-	for i := len(r.app.initSequence) - 1; i >= 0; i-- {
-		id := r.app.initSequence[i]
-		meta := r.app.GetComponentMetaById(id)
-		if meta == nil {
+	for _, id := range r.app.closeOrder() {
+		r.app.lock.RLock()
+		meta, ok := r.app.components[id]
+		if !ok {
+			meta, ok = r.app.instances[id]
+		}
+		r.app.lock.RUnlock()
+		if !ok {
 			return errors.New("component meta not found: " + id)
 		}
+		end := r.profiler.span(profileClose, id)
 		meta.close()
+		end()
 	}
 	err := r.conf.Close()
 	if err != nil {
@@ -91,18 +133,45 @@ type ComponentInitializer struct {
 	afterHandlers         map[string][]AfterInitHandler
 	beforeHandlers        map[string][]BeforeInitHandler
 	afterCount            map[string]int
+	afterTargets          map[string]Component
 	beforeCount           map[string]int
 	componentStatus       map[string]struct{}
 	app                   *AppContext
 	conf                  *ConfContext
 	initSeq               []string
 	initChain             []string
+	parallelism           int
+	profiler              *profiler
+	ctx                   context.Context
 	logger                Logger
+	lock                  sync.Mutex
+	// guards graph of components which changes at runtime
+	graphLock sync.RWMutex
+	// ids of componentGraph in order, kept sorted when the graph changes
+	sortedGraphIds []string
+	typeIndex      *typeIndex
 }
 
 func newComponentInitializer(graph map[string]*ComponentMeta[Component], app *AppContext, conf *ConfContext, afterHandlers map[string][]AfterInitHandler, beforeHandlers map[string][]BeforeInitHandler) (*ComponentInitializer, error) {
-	m := map[string][]*ComponentMeta[Component]{}
-	primary := map[string]*ComponentMeta[Component]{}
+	m, primary, errs := groupComponents(graph)
+	if len(errs) > 0 {
+		return nil, joinErrors(errs)
+	}
+	ci := &ComponentInitializer{
+		componentGraph:       graph,
+		componentGroupByType: m,
+		sortedGraphIds:       sortedIds(graph),
+		typeIndex:            newTypeIndex(graph),
+	}
+	// handlers registered by short type name
+	afterHandlers, err := resolveHandlers(ci, afterHandlers)
+	if err != nil {
+		return nil, err
+	}
+	beforeHandlers, err = resolveHandlers(ci, beforeHandlers)
+	if err != nil {
+		return nil, err
+	}
 	afterCount := map[string]int{}
 	beforeCount := map[string]int{}
 	for _, c := range graph {
@@ -123,41 +192,70 @@ func newComponentInitializer(graph map[string]*ComponentMeta[Component], app *Ap
 				beforeCount[ct] = 1
 			}
 		}
+	}
+	ci.componentPrimaryGraph = primary
+	ci.componentStatus = map[string]struct{}{}
+	ci.afterHandlers = afterHandlers
+	ci.beforeHandlers = beforeHandlers
+	ci.afterCount = afterCount
+	ci.afterTargets = map[string]Component{}
+	ci.beforeCount = beforeCount
+	ci.app = app
+	ci.conf = conf
+	ci.ctx = context.Background()
+	ci.logger = app.MainLog
+	return ci, nil
+}
 
-		// find if duplicated
-		if _, ok := m[ct]; ok {
-			m[ct] = append(m[ct], c)
-		} else {
-			m[ct] = []*ComponentMeta[Component]{c}
+func resolveHandlers[H any](ci *ComponentInitializer, handlers map[string][]H) (map[string][]H, error) {
+	resolved := make(map[string][]H, len(handlers))
+	for ct, hs := range handlers {
+		t, err := ci.resolveType(ct)
+		if err != nil {
+			return nil, newStartError(PhaseInit, "", err)
 		}
+		resolved[t] = append(resolved[t], hs...)
+	}
+	return resolved, nil
+}
+
+// groupComponents groups components by type and finds the primary one of each type
+func groupComponents(graph map[string]*ComponentMeta[Component]) (map[string][]*ComponentMeta[Component], map[string]*ComponentMeta[Component], []error) {
+	var errs []error
+	m := map[string][]*ComponentMeta[Component]{}
+	primary := map[string]*ComponentMeta[Component]{}
+	for _, id := range sortedIds(graph) {
+		c := graph[id]
+		ct := string(c.Type())
+		m[ct] = append(m[ct], c)
 		if c.IsPrimary() {
 			if meta, exist := primary[ct]; exist {
-				return nil, errors.New("duplicated primary component " + ct + ": " + c.componentID + ", " + meta.componentID)
+				errs = append(errs, newStartError(PhaseInit, c.componentID, fmt.Errorf("%w %s: %s, %s", ErrDuplicatePrimary, ct, c.componentID, meta.componentID)))
+				continue
 			}
 			primary[ct] = c
 		}
 	}
-	ci := &ComponentInitializer{
-		componentGraph:        graph,
-		componentGroupByType:  m,
-		componentPrimaryGraph: primary,
-		componentStatus:       map[string]struct{}{},
-		afterHandlers:         afterHandlers,
-		beforeHandlers:        beforeHandlers,
-		afterCount:            afterCount,
-		beforeCount:           beforeCount,
-		app:                   app,
-		conf:                  conf,
-		logger:                app.MainLog,
+	return m, primary, errs
+}
+
+func sortedIds(graph map[string]*ComponentMeta[Component]) []string {
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
 	}
-	return ci, nil
+	slices.Sort(ids)
+	return ids
 }
 
 func (ci *ComponentInitializer) InitializeAll() ([]string, error) {
+	if ci.parallelism > 0 {
+		return ci.initializeLevels()
+	}
 	// init no
 	for _, c := range ci.componentGraph {
 		if len(c.Dependencies()) == 0 && len(c.DependencyTypes()) == 0 {
-			if c.IsInitialized() {
+			if c.IsInitialized() || c.isSkipped() {
 				continue
 			}
 			err := ci.InitializeOne(c)
@@ -167,7 +265,7 @@ func (ci *ComponentInitializer) InitializeAll() ([]string, error) {
 		}
 	}
 	for _, c := range ci.componentGraph {
-		if c.IsInitialized() {
+		if c.IsInitialized() || c.isSkipped() {
 			continue
 		}
 		ci.initChain = []string{}
@@ -180,72 +278,221 @@ func (ci *ComponentInitializer) InitializeAll() ([]string, error) {
 }
 
 func (ci *ComponentInitializer) InitializeOne(inMeta *ComponentMeta[Component]) error {
-	if slices.Contains(ci.initChain, inMeta.ID()) {
-		ci.initChain = append(ci.initChain, inMeta.ID())
-		idx := slices.Index(ci.initChain, inMeta.ID())
-		ci.initChain = ci.initChain[idx:]
-		return fmt.Errorf("circular dependencies found:%s", strings.Join(ci.initChain, " -> "))
+	err := ci.checkCircular(inMeta.ID())
+	if err != nil {
+		return err
 	}
 	ci.initChain = append(ci.initChain, inMeta.ID())
 	defer func() {
 		ci.initChain = ci.initChain[:len(ci.initChain)-1]
 	}()
-	if inMeta.IsInitialized() {
+	if inMeta.IsInitialized() || inMeta.isSkipped() {
 		return nil
 	}
-	types := inMeta.DependencyTypes()
-	for _, t := range types {
-		metas := ci.componentGroupByType[t]
-		if len(metas) == 0 && inMeta.IsAdditionalDepends(t) {
-			return fmt.Errorf("component type[%s] required by [%s] but found 0 candidates", t, inMeta.ID())
+	deps, err := ci.dependenciesOf(inMeta)
+	if err != nil {
+		return err
+	}
+	for _, m := range deps {
+		err = ci.InitializeOne(m)
+		if err != nil {
+			return err
 		}
-		for _, meta := range metas {
-			m := ci.getMetaById(meta.ID())
-			if m == nil {
-				return fmt.Errorf("component[%s] required by [%s] not found", meta.ID(), inMeta.ID())
-			}
-			err := ci.InitializeOne(m)
+	}
+	return ci.initialize(inMeta)
+}
+
+// initializeLevels groups components into topological levels and initializes
+// each level on a pool of at most ci.parallelism goroutines
+func (ci *ComponentInitializer) initializeLevels() ([]string, error) {
+	levels, err := ci.levels()
+	if err != nil {
+		return nil, err
+	}
+	sem := make(chan struct{}, ci.parallelism)
+	for _, level := range levels {
+		var wg sync.WaitGroup
+		errs := make([]error, len(level))
+		for i, meta := range level {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				errs[i] = ci.initialize(meta)
+			}()
+		}
+		wg.Wait()
+		if err = joinErrors(errs); err != nil {
+			return nil, err
+		}
+	}
+	return ci.initSeq, nil
+}
+
+// levels returns the components grouped by depth, every component only depends on components of lower levels
+func (ci *ComponentInitializer) levels() ([][]*ComponentMeta[Component], error) {
+	var levels [][]*ComponentMeta[Component]
+	depth := map[string]int{}
+	var visit func(inMeta *ComponentMeta[Component]) (int, error)
+	visit = func(inMeta *ComponentMeta[Component]) (int, error) {
+		err := ci.checkCircular(inMeta.ID())
+		if err != nil {
+			return 0, err
+		}
+		if d, ok := depth[inMeta.ID()]; ok {
+			return d, nil
+		}
+		ci.initChain = append(ci.initChain, inMeta.ID())
+		defer func() {
+			ci.initChain = ci.initChain[:len(ci.initChain)-1]
+		}()
+		deps, err := ci.dependenciesOf(inMeta)
+		if err != nil {
+			return 0, err
+		}
+		level := 0
+		for _, m := range deps {
+			d, err := visit(m)
 			if err != nil {
-				return err
+				return 0, err
 			}
+			level = max(level, d+1)
 		}
+		depth[inMeta.ID()] = level
+		for len(levels) <= level {
+			levels = append(levels, nil)
+		}
+		levels[level] = append(levels[level], inMeta)
+		return level, nil
 	}
-	instancies := inMeta.Dependencies()
-	for _, instance := range instancies {
-		m := ci.getMetaById(instance)
-		if m == nil {
-			return fmt.Errorf("component[%s] required by [%s] not found", instance, inMeta.ID())
+	for _, c := range ci.componentGraph {
+		ci.initChain = []string{}
+		_, err := visit(c)
+		if err != nil {
+			return nil, err
 		}
-		err := ci.InitializeOne(m)
+	}
+	return levels, nil
+}
+
+func (ci *ComponentInitializer) checkCircular(id string) error {
+	if !slices.Contains(ci.initChain, id) {
+		return nil
+	}
+	chain := append(slices.Clone(ci.initChain), id)
+	idx := slices.Index(chain, id)
+	return newStartError(PhaseInit, id, fmt.Errorf("%w found:%s", ErrCircularDependency, strings.Join(chain[idx:], " -> ")))
+}
+
+// dependenciesOf returns all dependencies of inMeta, every missing dependency is reported in the error
+func (ci *ComponentInitializer) dependenciesOf(inMeta *ComponentMeta[Component]) ([]*ComponentMeta[Component], error) {
+	var deps []*ComponentMeta[Component]
+	var errs []error
+	for _, t := range inMeta.DependencyTypes() {
+		rt, err := ci.resolveType(t)
 		if err != nil {
-			return err
+			errs = append(errs, newStartError(PhaseInit, inMeta.ID(), err))
+			continue
+		}
+		metas := ci.componentGroupByType[rt]
+		if len(metas) == 0 && inMeta.IsAdditionalDepends(t) {
+			errs = append(errs, newStartError(PhaseInit, inMeta.ID(), fmt.Errorf("%w: component type[%s] required by [%s] but found 0 candidates", ErrMissingDependency, t, inMeta.ID())))
+			continue
 		}
+		deps = append(deps, metas...)
 	}
+	for _, instance := range inMeta.Dependencies() {
+		id, err := ci.resolveID(instance)
+		if err != nil {
+			errs = append(errs, newStartError(PhaseInit, inMeta.ID(), err))
+			continue
+		}
+		m := ci.getMetaById(id)
+		if m == nil {
+			errs = append(errs, newStartError(PhaseInit, inMeta.ID(), fmt.Errorf("%w: component[%s] required by [%s] not found", ErrMissingDependency, instance, inMeta.ID())))
+			continue
+		}
+		deps = append(deps, m)
+	}
+	deps = append(deps, ci.interfaceDependencies(inMeta)...)
+	return deps, joinErrors(errs)
+}
+
+// interfaceDependencies returns implementations of interfaces which inMeta depends on
+func (ci *ComponentInitializer) interfaceDependencies(inMeta *ComponentMeta[Component]) []*ComponentMeta[Component] {
+	var deps []*ComponentMeta[Component]
+	for _, diInfo := range inMeta.injections() {
+		if diInfo.DependIface != nil {
+			deps = append(deps, ci.candidates(inMeta, diInfo)...)
+		}
+	}
+	return deps
+}
+
+func (ci *ComponentInitializer) initialize(inMeta *ComponentMeta[Component]) error {
 	// before handler
 	ct := string(inMeta.componentType)
 	ci.handleBefore(ct)
-	// dependency inject
-	err := ci.dependencyInject(inMeta)
-	if err != nil {
-		return err
+	if inMeta.provider != nil {
+		if err := ci.provide(inMeta); err != nil {
+			return ci.initFailed(inMeta, inMeta.fail(err))
+		}
+	}
+	// dependency inject, prototype is injected when building its instances
+	if !inMeta.IsPrototype() {
+		err := ci.dependencyInject(inMeta)
+		if err == nil {
+			err = ci.configInject(inMeta)
+		}
+		if err != nil {
+			return ci.initFailed(inMeta, inMeta.fail(err))
+		}
 	}
-	err = inMeta.init(ci.app, ci.conf)
+	end := ci.profiler.span(profileInit, inMeta.ID())
+	err := inMeta.init(ci.ctx, ci.app, inMeta.confOf(ci.conf))
+	end()
 	if err != nil {
-		return err
+		return ci.initFailed(inMeta, err)
 	}
 	if inMeta.IsLazyInit() {
 		ci.logger.Info("component", inMeta.ID(), "skip init cause lazy init")
+	} else if inMeta.IsPrototype() {
+		ci.logger.Info("component", inMeta.ID(), "skip init cause prototype")
 	} else {
+		ci.lock.Lock()
 		ci.initSeq = append(ci.initSeq, inMeta.ID())
+		ci.lock.Unlock()
 		ci.logger.Info("component", inMeta.ID(), "init success")
 	}
-	ci.app.addComponent(inMeta)
+	deps, err := ci.dependenciesOf(inMeta)
+	if err != nil {
+		return err
+	}
+	ci.app.addComponent(inMeta, deps)
+	if !inMeta.IsLazyInit() && !inMeta.IsPrototype() {
+		ci.app.addHealthCheck(inMeta)
+	}
 	// after handler
 	ci.handleAfter(ct, inMeta.component)
 	return nil
 }
 
+// initFailed skips the failed component if it ignores error, so that app runs in degraded mode
+func (ci *ComponentInitializer) initFailed(inMeta *ComponentMeta[Component], err error) error {
+	if inMeta.IsIgnoreError() {
+		ci.logger.Warnf("component %s init failed, skipped cause ignore error: %v", inMeta.ID(), err)
+		ci.app.addSkippedHealthCheck(inMeta, err)
+		ci.handleAfter(string(inMeta.componentType), nil)
+		return nil
+	}
+	return componentError(inMeta.ID(), err)
+}
+
 func (ci *ComponentInitializer) getMetaById(id string) *ComponentMeta[Component] {
+	id, _ = ci.resolveID(id)
 	return ci.componentGraph[id]
 }
 
@@ -263,58 +510,12 @@ func (ci *ComponentInitializer) dependencyInject(inMeta *ComponentMeta[Component
 		fieldT := t.Field(i)
 		if field.CanSet() {
 			if diInfo, ok := fields[fieldT.Name]; ok {
-				if ok {
-					var components []Component
-					componentMap := map[string]Component{}
-					if diInfo.IsDependAll {
-						if metas, ok2 := ci.componentGroupByType[string(diInfo.DependType)]; ok2 {
-							for _, meta := range metas {
-								components = append(components, meta.component)
-								componentMap[meta.componentName] = meta.component
-							}
-						}
-					} else {
-						for _, id := range diInfo.DependIds {
-							if meta, ok2 := ci.componentGraph[id]; ok2 {
-								components = append(components, meta.component)
-								componentMap[meta.componentName] = meta.component
-							}
-						}
-					}
-					if len(components) == 0 {
-						if diInfo.Required {
-							return errors.New(t.Name() + "." + diInfo.FieldName + " can not set, found 0 candidates, but field is required, or you can add \"required:false\" on field tag to avoid this")
-						}
-						continue
-					}
-					switch diInfo.FieldKind {
-					case reflect.Slice:
-						targetSlice := reflect.MakeSlice(diInfo.FieldType, len(components), len(components))
-						for i, c := range components {
-							cv := reflect.ValueOf(c)
-							targetSlice.Index(i).Set(cv)
-						}
-						field.Set(targetSlice)
-					case reflect.Map:
-						targetMap := reflect.MakeMap(diInfo.FieldType)
-						for k, c := range componentMap {
-							cv := reflect.ValueOf(c)
-							targetMap.SetMapIndex(reflect.ValueOf(k), cv)
-						}
-						field.Set(targetMap)
-					case reflect.Ptr:
-						if len(components) > 1 {
-							if p, ok := ci.componentPrimaryGraph[string(diInfo.DependType)]; ok {
-								vv := reflect.ValueOf(p.component)
-								field.Set(vv)
-							} else {
-								return errors.New(t.Name() + "." + diInfo.FieldName + " can not set, found more than 1 candidates, please specify name on tag or set primary when register component")
-							}
-						} else {
-							vv := reflect.ValueOf(components[0])
-							field.Set(vv)
-						}
-					}
+				value, err := ci.injectValue(inMeta, t.Name(), diInfo)
+				if err != nil {
+					return err
+				}
+				if value.IsValid() {
+					field.Set(value)
 				}
 			}
 		}
@@ -322,36 +523,224 @@ func (ci *ComponentInitializer) dependencyInject(inMeta *ComponentMeta[Component
 	return nil
 }
 
-func (ci *ComponentInitializer) handleBefore(ct string) error {
-	if handlers, exist := ci.beforeHandlers[ct]; exist {
-		if count, ok := ci.beforeCount[ct]; ok {
-			newCount := count - 1
-			ci.beforeCount[ct] = newCount
-			if newCount <= 0 {
-				ci.logger.Info("before_init:", ct)
-				for _, handler := range handlers {
-					handler(ci.app, ci.conf)
-				}
-				delete(ci.beforeCount, ct)
+// injectValue builds the value of a field or a provider parameter,
+// the returned value is invalid if nothing found and it is not required
+func (ci *ComponentInitializer) injectValue(owner *ComponentMeta[Component], typeName string, diInfo fieldInfo) (reflect.Value, error) {
+	var metas []*ComponentMeta[Component]
+	candidates := ci.candidates(owner, diInfo)
+	for _, meta := range candidates {
+		if meta.isSkipped() {
+			continue
+		}
+		metas = append(metas, meta)
+	}
+	if len(metas) == 0 {
+		if diInfo.Required && len(candidates) > 0 {
+			return reflect.Value{}, fmt.Errorf("%w: %s.%s can not set, all candidates failed to init, but field is required", ErrMissingDependency, typeName, diInfo.FieldName)
+		}
+		if diInfo.Required {
+			return reflect.Value{}, fmt.Errorf("%w: %s.%s can not set, found 0 candidates, but field is required, or you can add \"required:false\" on field tag to avoid this", ErrMissingDependency, typeName, diInfo.FieldName)
+		}
+		return reflect.Value{}, nil
+	}
+	switch diInfo.FieldKind {
+	case reflect.Slice:
+		targetSlice := reflect.MakeSlice(diInfo.FieldType, 0, len(metas))
+		for _, meta := range metas {
+			c, err := ci.resolve(owner, meta)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if c != nil {
+				targetSlice = reflect.Append(targetSlice, reflect.ValueOf(c))
+			}
+		}
+		return targetSlice, nil
+	case reflect.Map:
+		targetMap := reflect.MakeMap(diInfo.FieldType)
+		for _, meta := range metas {
+			c, err := ci.resolve(owner, meta)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if c == nil {
+				continue
+			}
+			targetMap.SetMapIndex(reflect.ValueOf(meta.componentName), reflect.ValueOf(c))
+		}
+		return targetMap, nil
+	case reflect.Ptr, reflect.Interface:
+		if len(metas) > 1 && owner.module != "" {
+			metas = sameModule(owner, metas)
+		}
+		target := metas[0]
+		if len(metas) > 1 {
+			p := ci.primaryOf(diInfo, metas)
+			if p == nil || p.isSkipped() {
+				return reflect.Value{}, fmt.Errorf("%s.%s can not set, %w, please specify name on tag or set primary when register component", typeName, diInfo.FieldName, ErrAmbiguousDependency)
+			}
+			target = p
+		}
+		c, err := ci.resolve(owner, target)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if c == nil {
+			if diInfo.Required {
+				return reflect.Value{}, fmt.Errorf("%w: %s.%s can not set, all candidates failed to init, but field is required", ErrMissingDependency, typeName, diInfo.FieldName)
+			}
+			return reflect.Value{}, nil
+		}
+		return reflect.ValueOf(c), nil
+	}
+	return reflect.Value{}, nil
+}
+
+// resolve returns the component injected into owner, every injection point of a prototype gets a new instance,
+// it is nil if the instance failed to init and the prototype ignores error
+func (ci *ComponentInitializer) resolve(owner, meta *ComponentMeta[Component]) (Component, error) {
+	if !meta.IsPrototype() {
+		return meta.component, nil
+	}
+	instance, err := ci.newInstance(owner, meta)
+	if err != nil {
+		if meta.IsIgnoreError() {
+			ci.logger.Warnf("instance of %s init failed, skipped cause ignore error: %v", meta.ID(), err)
+			return nil, nil
+		}
+		return nil, err
+	}
+	return instance.component, nil
+}
+
+// newInstance builds, injects and initializes an instance of prototype, the instance is closed after owner at shutdown,
+// instances without owner are fetched by GetComponent and closed at shutdown unless released by ReleaseComponent
+func (ci *ComponentInitializer) newInstance(owner, prototype *ComponentMeta[Component]) (*ComponentMeta[Component], error) {
+	instance := prototype.newInstance()
+	instance.fetched = owner == nil
+	err := ci.dependencyInject(instance)
+	if err == nil {
+		err = ci.configInject(instance)
+	}
+	if err != nil {
+		return nil, componentError(instance.ID(), instance.fail(err))
+	}
+	end := ci.profiler.span(profileInit, instance.ID())
+	err = instance.init(ci.ctx, ci.app, instance.confOf(ci.conf))
+	end()
+	if err != nil {
+		return nil, componentError(instance.ID(), err)
+	}
+	ci.app.addInstance(owner, prototype, instance)
+	return instance, nil
+}
+
+// primaryOf returns the primary one of candidates, nil if there is none or more than one primary implementation of interface
+func (ci *ComponentInitializer) primaryOf(diInfo fieldInfo, candidates []*ComponentMeta[Component]) *ComponentMeta[Component] {
+	if diInfo.DependIface == nil {
+		return ci.primary(string(diInfo.DependType))
+	}
+	var p *ComponentMeta[Component]
+	for _, meta := range candidates {
+		if meta.IsPrimary() {
+			if p != nil {
+				return nil
 			}
+			p = meta
 		}
 	}
+	return p
+}
+
+func (ci *ComponentInitializer) primary(componentType string) *ComponentMeta[Component] {
+	ci.graphLock.RLock()
+	defer ci.graphLock.RUnlock()
+	return ci.componentPrimaryGraph[componentType]
+}
+
+// candidates returns components could be injected by diInfo, owner itself is never a candidate of interface
+func (ci *ComponentInitializer) candidates(owner *ComponentMeta[Component], diInfo fieldInfo) []*ComponentMeta[Component] {
+	ci.graphLock.RLock()
+	defer ci.graphLock.RUnlock()
+	if diInfo.DependIface != nil {
+		var metas []*ComponentMeta[Component]
+		for _, id := range ci.sortedGraphIds {
+			meta := ci.componentGraph[id]
+			if meta == owner || !reflect.TypeOf(meta.component).Implements(diInfo.DependIface) {
+				continue
+			}
+			if diInfo.IsDependAll || slices.Contains(diInfo.DependNames, meta.componentName) {
+				metas = append(metas, meta)
+			}
+		}
+		return metas
+	}
+	if diInfo.IsDependAll {
+		t, _ := ci.resolveType(string(diInfo.DependType))
+		return slices.Clone(ci.componentGroupByType[t])
+	}
+	var metas []*ComponentMeta[Component]
+	for _, id := range diInfo.DependIds {
+		id, _ = ci.resolveID(id)
+		if meta, ok := ci.componentGraph[id]; ok {
+			metas = append(metas, meta)
+		}
+	}
+	return metas
+}
+
+// handleBefore runs handlers of ct outside the lock, so handlers can use the initializer
+func (ci *ComponentInitializer) handleBefore(ct string) error {
+	ci.lock.Lock()
+	var handlers []BeforeInitHandler
+	if count, ok := ci.beforeCount[ct]; ok {
+		newCount := count - 1
+		ci.beforeCount[ct] = newCount
+		if newCount <= 0 {
+			handlers = slices.Clone(ci.beforeHandlers[ct])
+			delete(ci.beforeCount, ct)
+		}
+	}
+	ci.lock.Unlock()
+	if len(handlers) == 0 {
+		return nil
+	}
+	ci.logger.Info("before_init:", ct)
+	for _, handler := range handlers {
+		end := ci.profiler.span(profileBefore, ct)
+		handler(ci.app, ci.conf)
+		end()
+	}
 	return nil
 }
 
+// handleAfter runs after handlers once all components of the type are initialized or skipped,
+// component is nil for a skipped one, handlers get the last initialized component
 func (ci *ComponentInitializer) handleAfter(ct string, component Component) error {
-	if handlers, exist := ci.afterHandlers[ct]; exist {
-		if count, ok := ci.afterCount[ct]; ok {
-			newCount := count - 1
-			ci.afterCount[ct] = newCount
-			if newCount <= 0 {
-				ci.logger.Info("after_init:", ct)
-				for _, handler := range handlers {
-					handler(ci.app, ci.conf, component)
-				}
-				delete(ci.afterCount, ct)
-			}
+	ci.lock.Lock()
+	var handlers []AfterInitHandler
+	if component != nil {
+		ci.afterTargets[ct] = component
+	}
+	if count, ok := ci.afterCount[ct]; ok {
+		newCount := count - 1
+		ci.afterCount[ct] = newCount
+		if newCount <= 0 {
+			handlers = slices.Clone(ci.afterHandlers[ct])
+			component = ci.afterTargets[ct]
+			delete(ci.afterCount, ct)
+			delete(ci.afterTargets, ct)
 		}
 	}
+	ci.lock.Unlock()
+	if len(handlers) == 0 || component == nil {
+		return nil
+	}
+	ci.logger.Info("after_init:", ct)
+	for _, handler := range handlers {
+		end := ci.profiler.span(profileAfter, ct)
+		handler(ci.app, ci.conf, component)
+		end()
+	}
 	return nil
 }
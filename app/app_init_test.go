@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type TestSlowComponent struct {
+	delay       time.Duration
+	initialized atomic.Bool
+	deps        []*TestSlowComponent
+	depsReady   bool
+}
+
+func (s *TestSlowComponent) Init(app *AppContext, conf *ConfContext) error {
+	time.Sleep(s.delay)
+	s.depsReady = true
+	for _, d := range s.deps {
+		if !d.initialized.Load() {
+			s.depsReady = false
+		}
+	}
+	s.initialized.Store(true)
+	return nil
+}
+func (s *TestSlowComponent) Close() error {
+	return nil
+}
+
+func TestComponentParallelInit(t *testing.T) {
+	app := App("demo")
+	app.WithParallelInit(4)
+	var Leaf, Root ComponentType = "ParallelLeaf", "ParallelRoot"
+	var leaves []*TestSlowComponent
+	for i := 0; i < 4; i++ {
+		leaf := &TestSlowComponent{delay: 100 * time.Millisecond}
+		leaves = append(leaves, leaf)
+		app.WithComponentMeta("leaf"+strconv.Itoa(i), NewComponentMeta[Component](Leaf, leaf))
+	}
+	root := &TestSlowComponent{deps: leaves}
+	app.WithComponentMeta("root", NewComponentMeta[Component](Root, root, WithDependencyTypes[Component](Leaf)))
+	start := time.Now()
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	if cost := time.Since(start); cost >= 300*time.Millisecond {
+		t.Fatalf("leaves should be initialized concurrently, cost %v", cost)
+	}
+	if !root.depsReady {
+		t.Fatal("root initialized before its dependencies")
+	}
+	if len(app.app.initSequence) != 5 || app.app.initSequence[4] != getComponentID(Root, "root") {
+		t.Fatalf("unexpected init sequence %v", app.app.initSequence)
+	}
+}
+
+func TestComponentParallelInitCircularDependencies(t *testing.T) {
+	app := App("demo")
+	app.WithParallelInit(2)
+	var E, F ComponentType = "ParallelE", "ParallelF"
+	app.WithComponentMeta("e", NewComponentMeta(E, NewFakeComponent("E"), WithDependencyTypes[Component](F)))
+	app.WithComponentMeta("f", NewComponentMeta(F, NewFakeComponent("F"), WithDependencyTypes[Component](E)))
+	if code := app.Start(); code != 4 {
+		t.Fatalf("circular dependencies should fail with exit code 4, got %d", code)
+	}
+}
+
+type TestHangingComponent struct {
+	canceled atomic.Bool
+}
+
+func (h *TestHangingComponent) Init(app *AppContext, conf *ConfContext) error {
+	select {}
+}
+func (h *TestHangingComponent) InitWithContext(ctx context.Context, app *AppContext, conf *ConfContext) error {
+	<-ctx.Done()
+	h.canceled.Store(true)
+	return ctx.Err()
+}
+func (h *TestHangingComponent) Close() error {
+	return nil
+}
+
+func TestComponentInitTimeout(t *testing.T) {
+	app := App("demo")
+	var Hanging ComponentType = "Hanging"
+	hanging := &TestHangingComponent{}
+	app.WithComponentMeta("hanging", NewComponentMeta[Component](Hanging, hanging, WithInitTimeout[Component](50*time.Millisecond)))
+	if code := app.Start(); code != 4 {
+		t.Fatalf("init timeout should fail with exit code 4, got %d", code)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !hanging.canceled.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("InitWithContext should observe the canceled context")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartupTimeout(t *testing.T) {
+	app := App("demo")
+	app.WithStartupTimeout(50 * time.Millisecond)
+	var Blocking ComponentType = "Blocking"
+	app.WithComponentMeta("blocking", NewComponentMeta[Component](Blocking, &TestSlowComponent{delay: time.Hour}))
+	start := time.Now()
+	if code := app.Start(); code != 4 {
+		t.Fatalf("startup timeout should fail with exit code 4, got %d", code)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("startup timeout not applied")
+	}
+}
+
+type TestBrokenCache struct {
+	SimpleComponent
+}
+
+func (c *TestBrokenCache) Init(app *AppContext, conf *ConfContext) error {
+	return errors.New("cache unavailable")
+}
+
+type TestDegradedService struct {
+	SimpleComponent
+	Cache *TestBrokenCache `ekit:"component;required:false"`
+}
+
+type TestStrictService struct {
+	SimpleComponent
+	Cache *TestBrokenCache `ekit:"component"`
+}
+
+func TestIgnoreError(t *testing.T) {
+	app := App("demo")
+	app.WithComponent(&TestBrokenCache{}, WithIgnoreError[Component])
+	service := &TestDegradedService{}
+	app.WithComponent(service)
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if service.Cache != nil {
+		t.Fatal("failed component should not be injected")
+	}
+	failed := 0
+	for _, status := range app.app.ComponentStates() {
+		if status.State == StateFailed {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("unexpected states %+v", app.app.ComponentStates())
+	}
+	report := app.app.Health(context.Background())
+	if h := report.Components[testID(&TestBrokenCache{})]; !report.Live || report.Ready || h.Ready || !h.Critical {
+		t.Fatalf("skipped component should not be ready: %+v", report)
+	}
+
+	app = App("demo")
+	app.WithComponent(&TestBrokenCache{}, WithIgnoreError[Component])
+	app.WithComponent(&TestStrictService{})
+	if err := app.Run(); !errors.Is(err, ErrMissingDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+type TestFlakyCache struct {
+	SimpleComponent
+	fail bool
+}
+
+func (c *TestFlakyCache) Init(app *AppContext, conf *ConfContext) error {
+	if c.fail {
+		return errors.New("cache unavailable")
+	}
+	return nil
+}
+
+func TestIgnoreErrorAfterHandler(t *testing.T) {
+	app := App("demo")
+	good := &TestFlakyCache{}
+	app.WithNamedComponent("good", good)
+	app.WithNamedComponent("bad", &TestFlakyCache{fail: true}, WithIgnoreError[Component])
+	var targets []Component
+	app.AfterComponentTypeInit("TestFlakyCache", func(app *AppContext, conf *ConfContext, target Component) {
+		targets = append(targets, target)
+	})
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0] != good {
+		t.Fatalf("after handler should run once for the initialized component: %v", targets)
+	}
+}
+
+type TestLazyComponent struct {
+	inits  atomic.Int32
+	err    error
+	closed *[]string
+	name   string
+}
+
+func (l *TestLazyComponent) Init(app *AppContext, conf *ConfContext) error {
+	l.inits.Add(1)
+	time.Sleep(10 * time.Millisecond)
+	return l.err
+}
+func (l *TestLazyComponent) Close() error {
+	*l.closed = append(*l.closed, l.name)
+	return nil
+}
+
+func TestLazyInitOnce(t *testing.T) {
+	app := App("demo")
+	var Lazy, LazyDep, LazyBroken ComponentType = "Lazy", "LazyDep", "LazyBroken"
+	var closed []string
+	lazy := &TestLazyComponent{name: "lazy", closed: &closed}
+	dep := &TestLazyComponent{name: "dep", closed: &closed}
+	broken := &TestLazyComponent{name: "broken", closed: &closed, err: errors.New("broken")}
+	app.WithComponentMeta("lazy", NewComponentMeta[Component](Lazy, lazy, WithLazyInit[Component], WithDependencyTypes[Component](LazyDep)))
+	app.WithComponentMeta("dep", NewComponentMeta[Component](LazyDep, dep, WithLazyInit[Component]))
+	app.WithComponentMeta("broken", NewComponentMeta[Component](LazyBroken, broken, WithLazyInit[Component]))
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if c, err := app.GetComponentByIdE(getComponentID(Lazy, "lazy")); err != nil || c != lazy {
+					t.Errorf("unexpected component %v, %v", c, err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				if c, err := app.GetComponentByIdE(getComponentID(LazyBroken, "broken")); err == nil || c != nil {
+					t.Errorf("lazy init error should be returned, got %v", c)
+				}
+			}()
+		}
+		wg.Wait()
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if lazy.inits.Load() != 1 || dep.inits.Load() != 1 || broken.inits.Load() != 1 {
+		t.Fatalf("lazy init should run exactly once: %d %d %d", lazy.inits.Load(), dep.inits.Load(), broken.inits.Load())
+	}
+	if !slices.Equal(closed, []string{"lazy", "dep"}) {
+		t.Fatalf("unexpected close order %v", closed)
+	}
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"dario.cat/mergo"
+)
+
+// Module bundles components, init handlers and default config under a config namespace,
+// it can be mounted several times by WithModule and every mount gets its own instances
+type Module struct {
+	name           string
+	components     []moduleComponent
+	beforeHandlers []moduleBeforeHandler
+	afterHandlers  []moduleAfterHandler
+	defaults       Conf
+}
+
+type moduleComponent struct {
+	name    string
+	factory func() Component
+	options []ComponentMetaOption[Component]
+}
+
+type moduleBeforeHandler struct {
+	componentType ComponentType
+	handler       BeforeInitHandler
+}
+
+type moduleAfterHandler struct {
+	componentType ComponentType
+	handler       AfterInitHandler
+}
+
+// NewModule creates a module, name is the default mount prefix
+func NewModule(name string) *Module {
+	return &Module{name: name, defaults: Conf{}}
+}
+
+func (m *Module) Name() string {
+	return m.name
+}
+
+// WithComponent adds a component built by factory on every mount
+func (m *Module) WithComponent(factory func() Component, options ...ComponentMetaOption[Component]) {
+	m.WithNamedComponent("", factory, options...)
+}
+
+func (m *Module) WithNamedComponent(name string, factory func() Component, options ...ComponentMetaOption[Component]) {
+	m.components = append(m.components, moduleComponent{name: name, factory: factory, options: options})
+}
+
+// BeforeComponentTypeInit adds a handler called with config under the mount prefix
+func (m *Module) BeforeComponentTypeInit(componentType ComponentType, handler BeforeInitHandler) {
+	m.beforeHandlers = append(m.beforeHandlers, moduleBeforeHandler{componentType: componentType, handler: handler})
+}
+
+// AfterComponentTypeInit adds a handler called for every component of the type in the mount,
+// once all components of the type are initialized
+func (m *Module) AfterComponentTypeInit(componentType ComponentType, handler AfterInitHandler) {
+	m.afterHandlers = append(m.afterHandlers, moduleAfterHandler{componentType: componentType, handler: handler})
+}
+
+// WithDefaultConfig sets config values relative to the mount prefix, loaded config wins
+func (m *Module) WithDefaultConfig(conf Conf) {
+	mergo.Map(&m.defaults, conf, mergo.WithOverride)
+}
+
+// WithModule mounts m under prefix, the module name is used when prefix is empty.
+// Components are named "prefix.name" and read config under prefix, named dependencies between components
+// of the module are resolved within the mount, and single dependencies prefer components of the same mount
+func (r *RootComponent) WithModule(m *Module, prefix string) {
+	if prefix == "" {
+		prefix = m.name
+	}
+	if prefix == "" || slices.Contains(r.modulePrefixes, prefix) {
+		r.setupComponentErr = append(r.setupComponentErr, fmt.Errorf("%w: %q", ErrDuplicateModule, prefix))
+		return
+	}
+	r.modulePrefixes = append(r.modulePrefixes, prefix)
+	ids := map[string]string{}
+	var instances []Component
+	var mounted []string
+	for _, mc := range m.components {
+		component := mc.factory()
+		name := mc.name
+		if name == "" {
+			name = ComponentTypeOf(component).ShortName()
+		}
+		ct := ComponentTypeOf(component)
+		id := getComponentID(ct, prefix+"."+name)
+		ids[getComponentID(ct, name)] = id
+		ids[getComponentID(ComponentType(ct.ShortName()), name)] = id
+		instances = append(instances, component)
+		mounted = append(mounted, id)
+		options := append(slices.Clone(mc.options), withModule[Component](prefix))
+		r.WithNamedComponent(prefix+"."+name, component, options...)
+	}
+	for _, id := range mounted {
+		if meta, ok := r.componentHolder[id]; ok {
+			meta.remapDependencies(ids)
+		}
+	}
+	for _, meta := range r.conditionalComponents {
+		if slices.Contains(mounted, meta.ID()) {
+			meta.remapDependencies(ids)
+		}
+	}
+	for _, h := range m.beforeHandlers {
+		handler := h.handler
+		r.BeforeComponentTypeInit(h.componentType, func(app *AppContext, conf *ConfContext) {
+			handler(app, conf.Sub(prefix))
+		})
+	}
+	for _, h := range m.afterHandlers {
+		handler := h.handler
+		r.AfterComponentTypeInit(h.componentType, func(app *AppContext, conf *ConfContext, target Component) {
+			for _, c := range instances {
+				// components dropped by conditions or failed with ignored error are not in the app
+				if meta := app.Meta(c); meta == nil || meta.isSkipped() {
+					continue
+				}
+				if reflect.TypeOf(c) == reflect.TypeOf(target) {
+					handler(app, conf.Sub(prefix), c)
+				}
+			}
+		})
+	}
+	if len(m.defaults) > 0 {
+		r.configDefaults = append(r.configDefaults, nestConf(prefix, copyConf(m.defaults)))
+	}
+}
+
+func withModule[T Component](prefix string) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.module = prefix
+	}
+}
+
+// Module returns the prefix of the module mount the component belongs to, empty if it is not in a module
+func (cm *ComponentMeta[T]) Module() string {
+	return cm.module
+}
+
+// confOf returns config under the module prefix of the component
+func (cm *ComponentMeta[T]) confOf(conf *ConfContext) *ConfContext {
+	if cm.module == "" {
+		return conf
+	}
+	return conf.Sub(cm.module)
+}
+
+// remapDependencies replaces ids of named dependencies by ids of the same mount, ids may use short type names
+func (cm *ComponentMeta[T]) remapDependencies(ids map[string]string) {
+	remap := func(id string) string {
+		if mounted, ok := ids[id]; ok {
+			return mounted
+		}
+		return id
+	}
+	for i, id := range cm.dependencies {
+		cm.dependencies[i] = remap(id)
+	}
+	for _, set := range []map[string]struct{}{cm.additionalDepends, cm.extendDepends} {
+		for id := range set {
+			if mounted := remap(id); mounted != id {
+				delete(set, id)
+				set[mounted] = struct{}{}
+			}
+		}
+	}
+	for name, fi := range cm.fieldInfo {
+		if len(fi.DependIds) == 0 {
+			continue
+		}
+		dependIds := make([]string, len(fi.DependIds))
+		for i, id := range fi.DependIds {
+			dependIds[i] = remap(id)
+		}
+		fi.DependIds = dependIds
+		cm.fieldInfo[name] = fi
+	}
+}
+
+// sameModule returns candidates of the module of owner, all candidates if there is none
+func sameModule(owner *ComponentMeta[Component], candidates []*ComponentMeta[Component]) []*ComponentMeta[Component] {
+	var metas []*ComponentMeta[Component]
+	for _, meta := range candidates {
+		if meta.module == owner.module {
+			metas = append(metas, meta)
+		}
+	}
+	if len(metas) == 0 {
+		return candidates
+	}
+	return metas
+}
+
+// copyConf copies nested maps of conf, so mounts do not share them
+func copyConf(conf map[string]any) map[string]any {
+	c := make(map[string]any, len(conf))
+	for k, v := range conf {
+		if m, ok := v.(map[string]any); ok {
+			v = copyConf(m)
+		}
+		c[k] = v
+	}
+	return c
+}
+
+// nestConf puts conf under the dotted prefix
+func nestConf(prefix string, conf Conf) *Conf {
+	var v map[string]any = conf
+	keys := strings.Split(prefix, ".")
+	for i := len(keys) - 1; i >= 0; i-- {
+		v = map[string]any{keys[i]: v}
+	}
+	c := Conf(v)
+	return &c
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+type TestModuleStore struct {
+	SimpleComponent
+	DSN string `ekit:"config:store.dsn"`
+}
+
+type TestModuleServer struct {
+	SimpleComponent
+	Store  *TestModuleStore `ekit:"component"`
+	Named  *TestModuleStore `ekit:"component:main"`
+	Port   int              `ekit:"config:port"`
+	Routes []string
+}
+
+func TestModule(t *testing.T) {
+	m := NewModule("http")
+	m.WithComponent(func() Component { return &TestModuleServer{} })
+	m.WithNamedComponent("main", func() Component { return &TestModuleStore{} })
+	m.WithDefaultConfig(Conf{"port": 8080, "store": map[string]any{"dsn": "memory"}})
+	var befores []string
+	m.BeforeComponentTypeInit("TestModuleServer", func(app *AppContext, conf *ConfContext) {
+		befores = append(befores, conf.Value("store.dsn").String())
+	})
+	m.AfterComponentTypeInit("TestModuleServer", func(app *AppContext, conf *ConfContext, target Component) {
+		s := target.(*TestModuleServer)
+		s.Routes = append(s.Routes, "/"+strconv.Itoa(conf.Value("port").Int()))
+	})
+	app := App("demo")
+	app.WithConfigLoader(&TestMapConfigLoader{conf: Conf{"admin": map[string]any{"port": 9090}}})
+	app.WithModule(m, "api")
+	app.WithModule(m, "admin")
+	var api, admin *TestModuleServer
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		api, _ = GetComponentById[*TestModuleServer](app, getComponentID(ComponentTypeOf(&TestModuleServer{}), "api.TestModuleServer"))
+		admin, _ = GetComponentById[*TestModuleServer](app, getComponentID(ComponentTypeOf(&TestModuleServer{}), "admin.TestModuleServer"))
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if api == nil || admin == nil || api == admin || api.Store == admin.Store {
+		t.Fatalf("module mounts are not isolated: %+v, %+v", api, admin)
+	}
+	if api.Store != api.Named || admin.Store != admin.Named || api.Store.DSN != "memory" {
+		t.Fatalf("unexpected module dependencies %+v, %+v", api, admin)
+	}
+	if api.Port != 8080 || admin.Port != 9090 || !slices.Equal(api.Routes, []string{"/8080"}) || !slices.Equal(admin.Routes, []string{"/9090"}) {
+		t.Fatalf("unexpected module config %+v, %+v", api, admin)
+	}
+	if len(befores) != 2 || befores[0] != "memory" {
+		t.Fatalf("unexpected before handlers %v", befores)
+	}
+
+	app = App("demo")
+	app.WithModule(m, "api")
+	app.WithModule(m, "api")
+	if err := app.Run(); !errors.Is(err, ErrDuplicateModule) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestModuleFilters(t *testing.T) {
+	m := NewModule("jobs")
+	m.WithNamedComponent("main", func() Component { return &TestModuleStore{} })
+	m.WithComponent(func() Component { return &TestRedisCache{} }, WithDependencies[Component]("TestModuleStore:main"))
+	m.WithNamedComponent("a", func() Component { return &TestMemoryCache{} })
+	m.WithNamedComponent("b", func() Component { return &TestMemoryCache{} }, WithCondition[Component](OnConfig("b", "on")))
+	m.WithDefaultConfig(Conf{"store": map[string]any{"dsn": "memory"}})
+	var targets []Component
+	var global string
+	m.AfterComponentTypeInit("TestMemoryCache", func(app *AppContext, conf *ConfContext, target Component) {
+		targets = append(targets, target)
+		global = conf.Root().Value("global").String()
+	})
+	app := App("demo")
+	app.WithConfigLoader(&TestMapConfigLoader{conf: Conf{"global": "root", "more": map[string]any{"b": "on"}}})
+	app.WithModule(m, "")
+	app.WithModule(m, "more")
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 3 || global != "root" {
+		t.Fatalf("unexpected after handler targets %v, global %q", targets, global)
+	}
+}
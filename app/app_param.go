@@ -0,0 +1,109 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrParamNotFound = errors.New("the param not found")
+var ErrParamTypeMissMatch = errors.New("found param but type do not match")
+
+// ParamListener is called after the param is set
+type ParamListener func(name string, value any)
+
+// Params is a concurrency safe store of application params
+type Params struct {
+	lock      sync.RWMutex
+	values    map[string]any
+	listeners map[string]map[int]ParamListener
+	notifying map[string]*sync.Mutex
+	nextId    int
+}
+
+func newParams(values map[string]any) *Params {
+	p := &Params{
+		values:    map[string]any{},
+		listeners: map[string]map[int]ParamListener{},
+		notifying: map[string]*sync.Mutex{},
+	}
+	for k, v := range values {
+		p.values[k] = v
+	}
+	return p
+}
+
+func (p *Params) Get(name string) (any, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	v, ok := p.values[name]
+	return v, ok
+}
+
+// Set stores the param and notifies its listeners, notifications of a param are delivered in the order of writes,
+// so a listener must not set the param it listens
+func (p *Params) Set(name string, value any) {
+	p.lock.Lock()
+	notifying := p.notifying[name]
+	if notifying == nil {
+		notifying = &sync.Mutex{}
+		p.notifying[name] = notifying
+	}
+	p.lock.Unlock()
+	notifying.Lock()
+	defer notifying.Unlock()
+	p.lock.Lock()
+	p.values[name] = value
+	listeners := make([]ParamListener, 0, len(p.listeners[name]))
+	for _, l := range p.listeners[name] {
+		listeners = append(listeners, l)
+	}
+	p.lock.Unlock()
+	for _, l := range listeners {
+		l(name, value)
+	}
+}
+
+// Subscribe listens changes of the param, call the returned function to unsubscribe
+func (p *Params) Subscribe(name string, listener ParamListener) func() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	id := p.nextId
+	p.nextId++
+	if p.listeners[name] == nil {
+		p.listeners[name] = map[int]ParamListener{}
+	}
+	p.listeners[name][id] = listener
+	return func() {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		delete(p.listeners[name], id)
+	}
+}
+
+// GetParam returns the param as T, values not of T are converted like ConfValue, e.g. "8080" to int
+func GetParam[T any](app *AppContext, name string) (T, error) {
+	var t T
+	v, ok := app.params.Get(name)
+	if !ok {
+		return t, ErrParamNotFound
+	}
+	if tv, ok := v.(T); ok {
+		return tv, nil
+	}
+	target := reflect.ValueOf(&t).Elem()
+	if err := setConfValue(target, ConfValue{value: v}); err != nil {
+		return t, fmt.Errorf("%w: %s is %T: %w", ErrParamTypeMissMatch, name, v, err)
+	}
+	return t, nil
+}
+
+// MustParam is like GetParam but panics if the param is not found or can not be converted
+func MustParam[T any](app *AppContext, name string) T {
+	t, err := GetParam[T](app, name)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
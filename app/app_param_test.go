@@ -0,0 +1,65 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type TestParamComponent struct {
+	SimpleComponent
+	Path  string `ekit:"param:configPath"`
+	Port  int    `ekit:"param:port"`
+	Debug bool   `ekit:"param:debug;default:true"`
+}
+
+func TestParams(t *testing.T) {
+	app := App("demo")
+	app.WithParam("configPath", "/etc/demo.yaml")
+	app.WithParam("port", "8080")
+	app.WithParam("name", "demo")
+	c := &TestParamComponent{}
+	app.WithComponent(c)
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		if port, err := GetParam[int](app, "port"); err != nil || port != 8080 {
+			t.Errorf("unexpected port %d, %v", port, err)
+		}
+		if _, err := GetParam[int](app, "name"); !errors.Is(err, ErrParamTypeMissMatch) {
+			t.Errorf("unexpected error %v", err)
+		}
+		if _, err := GetParam[string](app, "unknown"); !errors.Is(err, ErrParamNotFound) {
+			t.Errorf("unexpected error %v", err)
+		}
+		var received atomic.Int32
+		var last atomic.Value
+		unsubscribe := app.SubscribeParam("port", func(name string, value any) {
+			received.Add(1)
+			last.Store(value)
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				app.SetParam("port", 9000+i)
+				MustParam[int](app, "port")
+			}()
+		}
+		wg.Wait()
+		if port := MustParam[int](app, "port"); last.Load() != port {
+			t.Errorf("last notification %v, param is %d", last.Load(), port)
+		}
+		unsubscribe()
+		app.SetParam("port", 1)
+		if received.Load() != 10 {
+			t.Errorf("unexpected notifications %d", received.Load())
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Path != "/etc/demo.yaml" || c.Port != 8080 || !c.Debug {
+		t.Fatalf("unexpected params %+v", c)
+	}
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	profileConfig = "config"
+	profileLog    = "log"
+	profileBefore = "before_init"
+	profileInit   = "init"
+	profileAfter  = "after_init"
+	profileRun    = "run"
+	profileOnExit = "on_exit"
+	profileClose  = "close"
+)
+
+type profileSpan struct {
+	category string
+	name     string
+	start    time.Time
+	duration time.Duration
+	instant  bool
+}
+
+// profiler records timing of startup and shutdown, all methods are safe on nil profiler
+type profiler struct {
+	lock      sync.Mutex
+	origin    time.Time
+	spans     []profileSpan
+	traceFile string
+}
+
+// WithStartupProfiler logs timing of config loading, logger init, handlers, Init, Run, OnExit and Close,
+// and writes a chrome trace_event file if traceFile is not empty
+func (r *RootComponent) WithStartupProfiler(traceFile string) {
+	r.profiler = &profiler{
+		origin:    time.Now(),
+		traceFile: traceFile,
+	}
+}
+
+// span starts a span, the returned func ends it
+func (p *profiler) span(category, name string) func() {
+	if p == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		p.add(profileSpan{category: category, name: name, start: start, duration: time.Since(start)})
+	}
+}
+
+func (p *profiler) instant(category, name string) {
+	if p == nil {
+		return
+	}
+	p.add(profileSpan{category: category, name: name, start: time.Now(), instant: true})
+}
+
+func (p *profiler) add(span profileSpan) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.spans = append(p.spans, span)
+}
+
+// mark returns the count of recorded spans, used to summarize spans recorded later
+func (p *profiler) mark() int {
+	if p == nil {
+		return 0
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.spans)
+}
+
+// summary renders spans recorded since mark as a table sorted by duration
+func (p *profiler) summary(mark int) []string {
+	if p == nil {
+		return nil
+	}
+	p.lock.Lock()
+	spans := slices.Clone(p.spans[mark:])
+	p.lock.Unlock()
+	slices.SortStableFunc(spans, func(a, b profileSpan) int {
+		return int(b.duration - a.duration)
+	})
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	w.Write([]byte("PHASE\tNAME\tSTART\tDURATION\n"))
+	for _, s := range spans {
+		duration := s.duration.String()
+		if s.instant {
+			duration = "-"
+		}
+		w.Write([]byte(s.category + "\t" + s.name + "\t+" + s.start.Sub(p.origin).String() + "\t" + duration + "\n"))
+	}
+	w.Flush()
+	return strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n")
+}
+
+type traceEvent struct {
+	Name     string `json:"name"`
+	Category string `json:"cat"`
+	Phase    string `json:"ph"`
+	Ts       int64  `json:"ts"`
+	Duration int64  `json:"dur,omitempty"`
+	Pid      int    `json:"pid"`
+	Tid      int    `json:"tid"`
+	Scope    string `json:"s,omitempty"`
+}
+
+// writeTrace writes all spans in chrome trace_event format, overlapped spans are put on different threads
+func (p *profiler) writeTrace() error {
+	if p == nil || p.traceFile == "" {
+		return nil
+	}
+	p.lock.Lock()
+	spans := slices.Clone(p.spans)
+	p.lock.Unlock()
+	slices.SortStableFunc(spans, func(a, b profileSpan) int {
+		return a.start.Compare(b.start)
+	})
+	var laneEnds []time.Time
+	events := make([]traceEvent, 0, len(spans))
+	for _, s := range spans {
+		event := traceEvent{
+			Name:     s.name,
+			Category: s.category,
+			Ts:       s.start.Sub(p.origin).Microseconds(),
+			Pid:      1,
+		}
+		if s.instant {
+			event.Phase = "i"
+			event.Scope = "g"
+			events = append(events, event)
+			continue
+		}
+		lane := slices.IndexFunc(laneEnds, func(end time.Time) bool {
+			return !end.After(s.start)
+		})
+		if lane == -1 {
+			lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+		}
+		laneEnds[lane] = s.start.Add(s.duration)
+		event.Phase = "X"
+		event.Duration = max(s.duration.Microseconds(), 1)
+		event.Tid = lane + 1
+		events = append(events, event)
+	}
+	data, err := json.Marshal(map[string]any{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p.traceFile, data, 0644)
+}
+
+// flushFailedProfile logs the summary and writes the trace file when startup failed, before logger initialized it prints to stdout
+func (r *RootComponent) flushFailedProfile() {
+	if r.profiler == nil {
+		return
+	}
+	if r.logger != nil {
+		r.logProfile("failed startup profile", 0)
+	} else {
+		fmt.Println("failed startup profile:")
+		for _, line := range r.profiler.summary(0) {
+			fmt.Println(line)
+		}
+	}
+	if err := r.profiler.writeTrace(); err != nil {
+		fmt.Println("failed to write trace file:", err.Error())
+	}
+}
+
+func (r *RootComponent) logProfile(title string, mark int) {
+	if r.profiler == nil {
+		return
+	}
+	r.logger.Info(title + ":")
+	for _, line := range r.profiler.summary(mark) {
+		r.logger.Info(line)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfile(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.json")
+	app := App("demo")
+	app.WithStartupProfiler(traceFile)
+	app.WithComponentMeta("slow", NewComponentMeta[Component]("ProfileSlow", &TestSlowComponent{delay: 10 * time.Millisecond}))
+	app.WithComponentMeta("runner", NewComponentMeta[Component]("ProfileRunner", &TestFailingRunnable{}))
+	app.BeforeComponentTypeInit("ProfileSlow", func(app *AppContext, conf *ConfContext) {})
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	if err = json.Unmarshal(data, &trace); err != nil {
+		t.Fatal(err)
+	}
+	categories := map[string]bool{}
+	for _, e := range trace.TraceEvents {
+		categories[e.Category] = true
+		if e.Name == "ProfileSlow:slow" && e.Category == profileInit && e.Duration < 10000 {
+			t.Fatalf("unexpected init duration %+v", e)
+		}
+	}
+	for _, c := range []string{profileConfig, profileLog, profileBefore, profileInit, profileRun, profileClose} {
+		if !categories[c] {
+			t.Fatalf("%s not profiled: %s", c, data)
+		}
+	}
+}
+
+func TestProfileOnStartupFailure(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.json")
+	app := App("demo")
+	app.WithStartupProfiler(traceFile)
+	app.WithStartupTimeout(50 * time.Millisecond)
+	app.WithComponentMeta("blocking", NewComponentMeta[Component]("Blocking", &TestSlowComponent{delay: time.Hour}))
+	if code := app.Start(); code != 4 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"cat":"`+profileConfig+`"`) {
+		t.Fatalf("startup not profiled: %s", data)
+	}
+}
@@ -0,0 +1,134 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type TestPrototypeStore struct {
+	SimpleComponent
+	closed *[]string
+}
+
+func (s *TestPrototypeStore) Close() error {
+	*s.closed = append(*s.closed, "store")
+	return nil
+}
+
+type TestPrototypeSession struct {
+	SimpleComponent
+	Store  *TestPrototypeStore `ekit:"component"`
+	inited bool
+	closed *[]string
+}
+
+func (s *TestPrototypeSession) Init(app *AppContext, conf *ConfContext) error {
+	s.inited = true
+	return nil
+}
+func (s *TestPrototypeSession) Close() error {
+	*s.closed = append(*s.closed, "session")
+	return nil
+}
+
+type TestPrototypeConsumer struct {
+	SimpleComponent
+	Session *TestPrototypeSession `ekit:"component"`
+}
+
+type TestPrototypeHandler struct {
+	SimpleComponent
+	Session *TestPrototypeSession `ekit:"component"`
+	closed  *[]string
+}
+
+func (h *TestPrototypeHandler) Close() error {
+	*h.closed = append(*h.closed, "handler")
+	return nil
+}
+
+type TestPrototypeBroken struct {
+	SimpleComponent
+}
+
+func (b *TestPrototypeBroken) Init(app *AppContext, conf *ConfContext) error {
+	return errors.New("broken")
+}
+
+type TestPrototypeOptional struct {
+	SimpleComponent
+	Broken *TestPrototypeBroken `ekit:"component;required:false"`
+}
+
+func TestPrototype(t *testing.T) {
+	app := App("demo")
+	var closed []string
+	app.WithComponent(&TestPrototypeStore{closed: &closed})
+	var built int
+	app.WithPrototypeComponent(func() Component {
+		built++
+		return &TestPrototypeSession{closed: &closed}
+	})
+	app.WithPrototypeComponent(func() Component {
+		return &TestPrototypeHandler{closed: &closed}
+	})
+	a, b := &TestPrototypeConsumer{}, &TestPrototypeConsumer{}
+	app.WithNamedComponent("a", a)
+	app.WithNamedComponent("b", b)
+	var fetched Component
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		tracked := len(app.instances)
+		for i := 0; i < 10; i++ {
+			if fetched != nil {
+				if err := app.ReleaseComponent(fetched); err != nil {
+					t.Error(err)
+				}
+			}
+			fetched = app.GetComponent("TestPrototypeSession", "TestPrototypeSession")
+		}
+		if len(app.instances) != tracked+1 || len(closed) != 9 {
+			t.Errorf("released instances should be closed and untracked, got %d instances, closed %v", len(app.instances), closed)
+		}
+		handler := app.GetComponent("TestPrototypeHandler", "TestPrototypeHandler")
+		if err := app.ReleaseComponent(handler); err != nil {
+			t.Error(err)
+		}
+		if !slices.Equal(closed[9:], []string{"handler", "session"}) || len(app.instances) != tracked+1 {
+			t.Errorf("instances injected into released instance should be released: %v", closed)
+		}
+		if err := app.ReleaseComponent(a.Session); !errors.Is(err, ErrNotFetchedInstance) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if a.Session == nil || a.Session == b.Session || !a.Session.inited || a.Session.Store == nil {
+		t.Fatalf("every injection point should get a new initialized instance: %+v %+v", a.Session, b.Session)
+	}
+	if s, ok := fetched.(*TestPrototypeSession); !ok || s == a.Session || s == b.Session || !s.inited {
+		t.Fatalf("GetComponent should build a new instance: %v", fetched)
+	}
+	sessions := 0
+	for _, c := range closed {
+		if c == "session" {
+			sessions++
+		}
+	}
+	if sessions != 13 || closed[len(closed)-1] != "store" {
+		t.Fatalf("unexpected close order %v", closed)
+	}
+	if built != 13 {
+		t.Fatalf("factory should be called once per instance, got %d", built)
+	}
+
+	app = App("demo")
+	app.WithPrototypeComponent(func() Component { return &TestPrototypeBroken{} }, WithIgnoreError[Component])
+	optional := &TestPrototypeOptional{}
+	app.WithComponent(optional)
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil || optional.Broken != nil {
+		t.Fatalf("failed instance should be skipped when prototype ignores error: %v", err)
+	}
+}
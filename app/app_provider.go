@@ -0,0 +1,176 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	appContextType  = reflect.TypeOf((*AppContext)(nil))
+	confContextType = reflect.TypeOf((*ConfContext)(nil))
+	componentIface  = reflect.TypeOf((*Component)(nil)).Elem()
+	errorIface      = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// provider builds a component by a constructor function, dependencies are read from types of its parameters
+type provider struct {
+	fn     reflect.Value
+	params []fieldInfo
+}
+
+// WithProvider registers the result of fn as a component, fn looks like func(a *A, b []*B, conf *ConfContext) (*C, error),
+// parameters can be *AppContext, *ConfContext, pointer or interface, slice or string-key map of components,
+// pointer and interface parameters are required, the result is injected by its tags and initialized by Init after fn returns.
+// Provider can not be lazy init
+func (r *RootComponent) WithProvider(fn any, options ...ComponentMetaOption[Component]) {
+	r.WithNamedProvider("", fn, options...)
+}
+
+func (r *RootComponent) WithNamedProvider(name string, fn any, options ...ComponentMetaOption[Component]) {
+	meta, err := buildProviderMeta(fn, options...)
+	if err != nil {
+		r.setupComponentErr = append(r.setupComponentErr, err)
+		return
+	}
+	if name == "" {
+		name = meta.componentType.ShortName()
+	}
+	r.WithComponentMeta(name, meta)
+}
+
+// RegisterProvider registers the result of fn to the running app like WithProvider
+func (a *AppContext) RegisterProvider(fn any, options ...ComponentMetaOption[Component]) error {
+	return a.RegisterNamedProvider("", fn, options...)
+}
+
+func (a *AppContext) RegisterNamedProvider(name string, fn any, options ...ComponentMetaOption[Component]) error {
+	meta, err := buildProviderMeta(fn, options...)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = meta.componentType.ShortName()
+	}
+	if err = meta.preInit(name); err != nil {
+		return err
+	}
+	return a.registerMeta(meta)
+}
+
+// buildProviderMeta resolves tags of the result type on a placeholder, which is replaced when the provider is called at init
+func buildProviderMeta(fn any, options ...ComponentMetaOption[Component]) (*ComponentMeta[Component], error) {
+	p, out, err := newProvider(fn)
+	if err != nil {
+		return nil, err
+	}
+	var types []ComponentType
+	for _, param := range p.params {
+		if param.DependType != "" {
+			types = append(types, param.DependType)
+		}
+	}
+	options = append(options, withDependencyTypes[Component](types...), withProvider[Component](p))
+	placeholder := reflect.New(out.Elem()).Interface().(Component)
+	meta, err := buildComponentMeta(placeholder, options...)
+	if err != nil {
+		return nil, err
+	}
+	if meta.IsLazyInit() {
+		return nil, fmt.Errorf("provider of %s can not be lazy init", meta.componentType)
+	}
+	return meta, nil
+}
+
+func withProvider[T Component](p *provider) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.provider = p
+	}
+}
+
+func newProvider(fn any) (*provider, reflect.Type, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, nil, fmt.Errorf("provider must be a function, got %T", fn)
+	}
+	t := v.Type()
+	if t.IsVariadic() {
+		return nil, nil, fmt.Errorf("provider must not be variadic: %s", t)
+	}
+	if t.NumOut() == 0 || t.NumOut() > 2 || (t.NumOut() == 2 && t.Out(1) != errorIface) {
+		return nil, nil, fmt.Errorf("provider must return a component and an optional error: %s", t)
+	}
+	out := t.Out(0)
+	if out.Kind() != reflect.Ptr || out.Elem().Kind() != reflect.Struct || !out.Implements(componentIface) {
+		return nil, nil, fmt.Errorf("provider must return pointer of a component struct: %s", t)
+	}
+	p := &provider{fn: v}
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		fi := fieldInfo{
+			FieldName: fmt.Sprintf("param%d", i),
+			FieldKind: in.Kind(),
+			FieldType: in,
+		}
+		if in != appContextType && in != confContextType {
+			elem := in
+			switch in.Kind() {
+			case reflect.Ptr, reflect.Interface:
+				fi.Required = true
+			case reflect.Slice:
+				elem = in.Elem()
+			case reflect.Map:
+				if in.Key().Kind() != reflect.String {
+					return nil, nil, fmt.Errorf("provider only support string-key map parameter: %s", t)
+				}
+				elem = in.Elem()
+			}
+			fi.IsDependAll = true
+			if elem.Kind() == reflect.Interface {
+				fi.DependIface = elem
+			} else if elem.Kind() == reflect.Ptr && elem.Elem().Kind() == reflect.Struct {
+				fi.DependType = ComponentType(qualifiedTypeName(elem.Elem()))
+			} else {
+				return nil, nil, fmt.Errorf("unsupported provider parameter %s: %s", in, t)
+			}
+		}
+		p.params = append(p.params, fi)
+	}
+	return p, out, nil
+}
+
+// provide calls the provider of inMeta, the result replaces the placeholder component
+func (ci *ComponentInitializer) provide(inMeta *ComponentMeta[Component]) error {
+	p := inMeta.provider
+	typeName := string(inMeta.componentType)
+	args := make([]reflect.Value, len(p.params))
+	for i, param := range p.params {
+		switch param.FieldType {
+		case appContextType:
+			args[i] = reflect.ValueOf(ci.app)
+		case confContextType:
+			args[i] = reflect.ValueOf(ci.conf)
+		default:
+			value, err := ci.injectValue(inMeta, typeName, param)
+			if err != nil {
+				return err
+			}
+			if !value.IsValid() {
+				value = reflect.Zero(param.FieldType)
+			}
+			args[i] = value
+		}
+	}
+	results := p.fn.Call(args)
+	if len(results) == 2 && !results[1].IsNil() {
+		return results[1].Interface().(error)
+	}
+	if results[0].IsNil() {
+		return errors.New("provider returns nil component")
+	}
+	// candidates read components under graph lock
+	ci.graphLock.Lock()
+	inMeta.component = results[0].Interface().(Component)
+	ci.graphLock.Unlock()
+	return nil
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type TestProvidedService struct {
+	SimpleComponent
+	store  *TestValidateStore
+	conf   *ConfContext
+	inited bool
+}
+
+func (s *TestProvidedService) Init(app *AppContext, conf *ConfContext) error {
+	s.inited = true
+	return nil
+}
+
+type TestProvidedConsumer struct {
+	SimpleComponent
+	Service *TestProvidedService `ekit:"component"`
+}
+
+func TestProvider(t *testing.T) {
+	app := App("demo")
+	store := &TestValidateStore{}
+	app.WithComponent(store)
+	var provided *TestProvidedService
+	app.WithProvider(func(store *TestValidateStore, conf *ConfContext, _ *AppContext) (*TestProvidedService, error) {
+		provided = &TestProvidedService{store: store, conf: conf}
+		return provided, nil
+	})
+	consumer := &TestProvidedConsumer{}
+	app.WithComponent(consumer)
+	app.WithComponent(&TestExitTrigger{})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if provided == nil || consumer.Service != provided || provided.store != store || provided.conf == nil || !provided.inited {
+		t.Fatalf("unexpected provided component %+v", provided)
+	}
+
+	app = App("demo")
+	app.WithProvider(func(cache *TestBrokenCache) *TestProvidedService {
+		return &TestProvidedService{}
+	})
+	if err := app.Validate(); !errors.Is(err, ErrMissingDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	app = App("demo")
+	app.WithProvider(func(store TestValidateStore) *TestProvidedService {
+		return &TestProvidedService{}
+	})
+	if err := app.Validate(); err == nil {
+		t.Fatal("invalid provider should be reported")
+	}
+	app = App("demo")
+	app.WithProvider(func() (*TestProvidedService, error) {
+		return nil, errors.New("provider failed")
+	})
+	var se *StartError
+	if err := app.Run(); !errors.As(err, &se) || se.ComponentID != testID(&TestProvidedService{}) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	app = App("demo")
+	app.WithProvider(func() *TestProvidedService {
+		return &TestProvidedService{}
+	}, WithLazyInit[Component])
+	if err := app.Run(); err == nil || !strings.Contains(err.Error(), "lazy") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	app = App("demo")
+	app.WithComponent(store)
+	var tagged, registered *TestTaggedProvided
+	app.WithProvider(func() *TestTaggedProvided {
+		tagged = &TestTaggedProvided{}
+		return tagged
+	})
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		err := app.RegisterNamedProvider("runtime", func(store *TestValidateStore) *TestTaggedProvided {
+			registered = &TestTaggedProvided{}
+			return registered
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		if id, err := GetComponentId(app, registered); err != nil || id != getComponentID(ComponentTypeOf(registered), "runtime") {
+			t.Errorf("unexpected id %s, %v", id, err)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*TestTaggedProvided{tagged, registered} {
+		if c == nil || c.Store != store || c.Name != "svc" {
+			t.Fatalf("tags of provided component should be injected %+v", c)
+		}
+	}
+}
+
+type TestTaggedProvided struct {
+	SimpleComponent
+	Store *TestValidateStore `ekit:"component"`
+	Name  string             `ekit:"config:service.name;default:svc"`
+}
@@ -3,57 +3,61 @@ package app
 import (
 	"context"
 	"errors"
-	"fmt"
 	"os"
 	"os/signal"
-	"syscall"
 	"time"
 )
 
-func (r *RootComponent) runAll() error {
-	var errs []error
-	idx := 0
-	ctx, cancel := context.WithCancel(context.Background())
+func (r *RootComponent) runAll(signals bool) error {
+	var runnables []*ComponentMeta[Component]
 	for _, c := range r.app.components {
-		if rc, ok := c.component.(RunnableComponent); ok {
-			errs = append(errs, nil)
-			r.runningWg.Add(1)
-			go func(i int) {
-				defer func() {
-					if err := recover(); err != nil {
-						errs[i] = fmt.Errorf("component[%s] panic at running:%v", c.ID(), err)
-					}
-					r.runningWg.Done()
-				}()
-				err := rc.Run(r.app, r.conf)
-				if err != nil {
-					errs[i] = err
-				}
-			}(idx)
-			idx++
+		if _, ok := c.component.(RunnableComponent); ok {
+			runnables = append(runnables, c)
 		}
 	}
+	errs := make([]error, len(runnables))
+	ctx, cancel := context.WithCancel(context.Background())
+	r.app.abortRun = cancel
+	for idx, c := range runnables {
+		r.startRunnable(c, func(err error) {
+			errs[idx] = newStartError(PhaseRun, c.ID(), err)
+		})
+	}
+	r.app.lock.Lock()
+	r.app.runComponent = func(meta *ComponentMeta[Component]) {
+		r.startRunnable(meta, func(err error) {
+			r.logger.Error(newStartError(PhaseRun, meta.ID(), err))
+		})
+	}
+	r.app.lock.Unlock()
 
 	go func() {
 		r.runningWg.Wait()
 		cancel()
 	}()
-	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	go func() {
-	EXIT:
-		for {
-			sig := <-sc
-			r.logger.Infof("got signal: %v\n", sig)
-			switch sig {
-			case syscall.SIGQUIT, syscall.SIGINT:
-				r.app.Exit("app canceled by user, stopping...")
-			case syscall.SIGTERM:
-				cancel()
-				break EXIT
-			}
+	if signals && len(r.signalHandlers) > 0 {
+		sc := make(chan os.Signal, 1)
+		var sigs []os.Signal
+		for sig := range r.signalHandlers {
+			sigs = append(sigs, sig)
 		}
-	}()
+		signal.Notify(sc, sigs...)
+		stopSignal := make(chan struct{})
+		defer func() {
+			signal.Stop(sc)
+			close(stopSignal)
+		}()
+		go func() {
+			for {
+				select {
+				case sig := <-sc:
+					r.handleSignal(sig)
+				case <-stopSignal:
+					return
+				}
+			}
+		}()
+	}
 	select {
 	case msg, ok := <-r.exitNotifyCh:
 		if !ok {
@@ -85,5 +89,24 @@ func (r *RootComponent) runAll() error {
 		}
 	}
 	r.app.setExited()
-	return errors.Join(errs...)
+	return joinErrors(errs)
+}
+
+// startRunnable supervises the component in a new goroutine, onErr receives the error it finally stopped with
+func (r *RootComponent) startRunnable(meta *ComponentMeta[Component], onErr func(err error)) {
+	done := make(chan struct{})
+	r.app.lock.Lock()
+	r.app.running[meta.ID()] = done
+	r.app.lock.Unlock()
+	r.runningWg.Add(1)
+	go func() {
+		defer func() {
+			close(done)
+			r.runningWg.Done()
+		}()
+		err := r.supervise(meta, meta.component.(RunnableComponent))
+		if err != nil {
+			onErr(err)
+		}
+	}()
 }
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type TestOrderedRunnable struct {
+	SimpleRunnableComponent
+	name    string
+	stopped *[]string
+	lock    *sync.Mutex
+}
+
+func (o *TestOrderedRunnable) OnExit() error {
+	time.Sleep(20 * time.Millisecond)
+	o.lock.Lock()
+	*o.stopped = append(*o.stopped, o.name)
+	o.lock.Unlock()
+	return o.SimpleRunnableComponent.OnExit()
+}
+
+func TestExitInReverseDependencyOrder(t *testing.T) {
+	app := App("demo")
+	var Router, Consumer ComponentType = "Router", "Consumer"
+	var stopped []string
+	var lock sync.Mutex
+	router := &TestOrderedRunnable{name: "router", stopped: &stopped, lock: &lock}
+	consumer := &TestOrderedRunnable{name: "consumer", stopped: &stopped, lock: &lock}
+	app.WithComponentMeta("router", NewComponentMeta[Component](Router, router, WithDependencyTypes[Component](Consumer)))
+	app.WithComponentMeta("consumer", NewComponentMeta[Component](Consumer, consumer))
+	app.WithComponentMeta("trigger", NewComponentMeta[Component]("Trigger", &TestExitTrigger{}))
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	if !slices.Equal(stopped, []string{"router", "consumer"}) {
+		t.Fatalf("router should exit before consumer: %v", stopped)
+	}
+}
+
+type TestDrainingRunnable struct {
+	TestOrderedRunnable
+}
+
+func (d *TestDrainingRunnable) Run(app *AppContext, conf *ConfContext) error {
+	<-d.Done()
+	time.Sleep(50 * time.Millisecond)
+	d.lock.Lock()
+	*d.stopped = append(*d.stopped, d.name+" drained")
+	d.lock.Unlock()
+	return nil
+}
+
+func TestExitWaitsForRun(t *testing.T) {
+	app := App("demo")
+	var Router, Queue ComponentType = "Router", "Queue"
+	var stopped []string
+	var lock sync.Mutex
+	router := &TestDrainingRunnable{TestOrderedRunnable{name: "router", stopped: &stopped, lock: &lock}}
+	queue := &TestOrderedRunnable{name: "queue", stopped: &stopped, lock: &lock}
+	app.WithComponentMeta("router", NewComponentMeta[Component](Router, router, WithDependencyTypes[Component](Queue)))
+	app.WithComponentMeta("queue", NewComponentMeta[Component](Queue, queue))
+	app.WithComponentMeta("trigger", NewComponentMeta[Component]("Trigger", &TestExitTrigger{}))
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	if !slices.Equal(stopped, []string{"router", "router drained", "queue"}) {
+		t.Fatalf("queue should exit after router drained: %v", stopped)
+	}
+}
+
+type TestBarrierRunnable struct {
+	SimpleRunnableComponent
+	name    string
+	barrier *sync.WaitGroup
+	started *[]string
+	lock    *sync.Mutex
+	alone   *atomic.Bool
+}
+
+func (b *TestBarrierRunnable) OnExit() error {
+	b.lock.Lock()
+	*b.started = append(*b.started, b.name)
+	b.lock.Unlock()
+	b.barrier.Done()
+	done := make(chan struct{})
+	go func() {
+		b.barrier.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		b.alone.Store(true)
+	}
+	return b.SimpleRunnableComponent.OnExit()
+}
+
+func TestConcurrentExit(t *testing.T) {
+	var Router, Consumer ComponentType = "Router", "Consumer"
+	run := func(concurrent bool) ([]string, bool) {
+		var started []string
+		var lock sync.Mutex
+		var alone atomic.Bool
+		routers, consumers := &sync.WaitGroup{}, &sync.WaitGroup{}
+		app := App("demo")
+		if concurrent {
+			app.WithConcurrentExit()
+			consumers = routers
+			routers.Add(3)
+		} else {
+			routers.Add(1)
+			consumers.Add(2)
+		}
+		newRunnable := func(name string, barrier *sync.WaitGroup) *TestBarrierRunnable {
+			return &TestBarrierRunnable{name: name, barrier: barrier, started: &started, lock: &lock, alone: &alone}
+		}
+		app.WithComponentMeta("router", NewComponentMeta[Component](Router, newRunnable("router", routers), WithDependencyTypes[Component](Consumer)))
+		app.WithComponentMeta("orders", NewComponentMeta[Component](Consumer, newRunnable("orders", consumers)))
+		app.WithComponentMeta("payments", NewComponentMeta[Component](Consumer, newRunnable("payments", consumers)))
+		app.WithComponentMeta("trigger", NewComponentMeta[Component]("Trigger", &TestExitTrigger{}))
+		if code := app.Start(); code != 0 {
+			t.Fatalf("unexpected exit code %d", code)
+		}
+		return started, alone.Load()
+	}
+	started, alone := run(false)
+	if alone || len(started) != 3 || started[0] != "router" {
+		t.Fatalf("consumers should exit together after router: %v, alone %v", started, alone)
+	}
+	started, alone = run(true)
+	if alone || len(started) != 3 {
+		t.Fatalf("all components should exit together: %v, alone %v", started, alone)
+	}
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Register adds component to the running app, dependencies are injected from the live graph,
+// then the component is initialized and run if it is runnable, ErrConditionNotMatched is returned when its conditions are false
+func (a *AppContext) Register(component Component, options ...ComponentMetaOption[Component]) error {
+	return a.RegisterNamed("", component, options...)
+}
+
+func (a *AppContext) RegisterNamed(name string, component Component, options ...ComponentMetaOption[Component]) error {
+	meta, err := buildComponentMeta(component, options...)
+	if err != nil {
+		return err
+	}
+	if componentProvider, ok := component.(ComponentProvider); ok {
+		for _, c := range componentProvider.EkitComponents() {
+			if err = a.Register(c); err != nil {
+				return err
+			}
+		}
+	}
+	if name == "" {
+		name = meta.componentType.ShortName()
+	}
+	if err = meta.preInit(name); err != nil {
+		return err
+	}
+	return a.registerMeta(meta)
+}
+
+func (a *AppContext) registerMeta(meta *ComponentMeta[Component]) error {
+	a.registerLock.Lock()
+	defer a.registerLock.Unlock()
+	a.lock.RLock()
+	ci, run := a.initializer, a.runComponent
+	_, exist := a.registered[meta.ID()]
+	_, singletonExist := a.singletonComponents[string(meta.componentType)]
+	a.lock.RUnlock()
+	if run == nil || a.isExiting() {
+		return ErrAppNotRunning
+	}
+	if exist {
+		return componentError(meta.ID(), fmt.Errorf("%w: %s", ErrDuplicateComponent, meta.ID()))
+	}
+	if meta.IsSingleton() && singletonExist {
+		return componentError(meta.ID(), fmt.Errorf("singleton %w: %s", ErrDuplicateComponent, meta.componentType))
+	}
+	a.lock.RLock()
+	ok, reason := meta.matchConditions(&ConditionContext{Conf: meta.confOf(a.conf), Params: a.params, types: a.typeIndex})
+	a.lock.RUnlock()
+	if !ok {
+		a.MainLog.Infof("component %s dropped: %s", meta.ID(), reason)
+		return componentError(meta.ID(), fmt.Errorf("%w: %s", ErrConditionNotMatched, reason))
+	}
+	ct := string(meta.componentType)
+	if p := ci.primary(ct); p != nil && meta.IsPrimary() {
+		return componentError(meta.ID(), fmt.Errorf("%w %s: %s, %s", ErrDuplicatePrimary, ct, meta.ID(), p.ID()))
+	}
+	ci.addMeta(meta)
+	a.register(meta)
+	ci.initChain = nil
+	if err := ci.InitializeOne(meta); err != nil || meta.isSkipped() {
+		ci.removeMeta(meta)
+		a.removeComponent(meta)
+		if err == nil {
+			err = componentError(meta.ID(), meta.LastError())
+		}
+		return err
+	}
+	if !meta.IsLazyInit() && !meta.IsPrototype() {
+		a.lock.Lock()
+		a.initSequence = append(a.initSequence, meta.ID())
+		a.lock.Unlock()
+	}
+	if _, ok := meta.component.(RunnableComponent); ok {
+		run(meta)
+	}
+	return nil
+}
+
+// Unregister stops and closes the component, it fails with ErrComponentInUse while other components depend on it
+func (a *AppContext) Unregister(id string) error {
+	return a.unregister(id, false)
+}
+
+// UnregisterCascade unregisters the component and all components depending on it, dependents are stopped first
+func (a *AppContext) UnregisterCascade(id string) error {
+	return a.unregister(id, true)
+}
+
+func (a *AppContext) unregister(id string, cascade bool) error {
+	a.registerLock.Lock()
+	defer a.registerLock.Unlock()
+	a.lock.RLock()
+	id, err := a.resolveID(id)
+	_, ok := a.registered[id]
+	ci := a.initializer
+	a.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrComponentNotFound
+	}
+	if ci == nil || a.isExiting() {
+		return ErrAppNotRunning
+	}
+	order := a.removeOrder(id)
+	if !cascade && len(order) > 1 {
+		return componentError(id, fmt.Errorf("%w: %v", ErrComponentInUse, order[:len(order)-1]))
+	}
+	var errs []error
+	for _, removeId := range order {
+		a.lock.RLock()
+		m := a.registered[removeId]
+		a.lock.RUnlock()
+		if m == nil {
+			continue
+		}
+		if err := a.stopComponent(m); err != nil {
+			errs = append(errs, componentError(removeId, err))
+		}
+		ci.removeMeta(m)
+		a.removeComponent(m)
+		if err := m.close(); err != nil {
+			errs = append(errs, componentError(removeId, err))
+		}
+		a.MainLog.Info("component", removeId, "unregistered")
+	}
+	return joinErrors(errs)
+}
+
+// removeOrder returns id and all components depending on it directly or indirectly, dependents come first
+func (a *AppContext) removeOrder(id string) []string {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	dependents := map[string][]string{}
+	for dependent, deps := range a.dependencies {
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], dependent)
+		}
+	}
+	var order []string
+	visited := map[string]struct{}{}
+	var visit func(id string)
+	visit = func(id string) {
+		if _, ok := visited[id]; ok {
+			return
+		}
+		visited[id] = struct{}{}
+		ids := dependents[id]
+		slices.Sort(ids)
+		for _, dependent := range ids {
+			visit(dependent)
+		}
+		order = append(order, id)
+	}
+	visit(id)
+	return order
+}
+
+// stopComponent calls OnExit of a running component and waits until its Run returns
+func (a *AppContext) stopComponent(meta *ComponentMeta[Component]) error {
+	meta.removed.Store(true)
+	rc, ok := meta.component.(RunnableComponent)
+	if !ok {
+		return nil
+	}
+	a.lock.RLock()
+	_, running := a.running[meta.ID()]
+	a.lock.RUnlock()
+	if !running {
+		return nil
+	}
+	if meta.State() == StateRunning {
+		meta.setState(StateStopping, nil)
+	}
+	err := meta.fail(rc.OnExit())
+	a.waitStopped(meta)
+	return err
+}
+
+func (a *AppContext) removeComponent(meta *ComponentMeta[Component]) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	id := meta.ID()
+	delete(a.components, id)
+	if _, ok := a.registered[id]; ok {
+		a.typeIndex.remove(string(meta.componentType))
+	}
+	delete(a.registered, id)
+	delete(a.dependencies, id)
+	delete(a.healthChecks, id)
+	delete(a.running, id)
+	if a.componentMetas[meta.component] == meta {
+		delete(a.componentMetas, meta.component)
+	}
+	if a.singletonComponents[string(meta.componentType)] == meta {
+		delete(a.singletonComponents, string(meta.componentType))
+	}
+	a.initSequence = slices.DeleteFunc(a.initSequence, func(s string) bool {
+		return s == id
+	})
+}
+
+func (ci *ComponentInitializer) addMeta(meta *ComponentMeta[Component]) {
+	ci.lock.Lock()
+	defer ci.lock.Unlock()
+	ci.graphLock.Lock()
+	defer ci.graphLock.Unlock()
+	ct := string(meta.componentType)
+	ci.componentGraph[meta.ID()] = meta
+	if i, found := slices.BinarySearch(ci.sortedGraphIds, meta.ID()); !found {
+		ci.sortedGraphIds = slices.Insert(ci.sortedGraphIds, i, meta.ID())
+	}
+	ci.componentGroupByType[ct] = append(ci.componentGroupByType[ct], meta)
+	ci.typeIndex.add(ct)
+	if meta.IsPrimary() {
+		ci.componentPrimaryGraph[ct] = meta
+	}
+	// handlers of the type run again for the component
+	if _, ok := ci.beforeHandlers[ct]; ok {
+		ci.beforeCount[ct] = 1
+	}
+	if _, ok := ci.afterHandlers[ct]; ok {
+		ci.afterCount[ct] = 1
+	}
+}
+
+func (ci *ComponentInitializer) removeMeta(meta *ComponentMeta[Component]) {
+	ci.graphLock.Lock()
+	defer ci.graphLock.Unlock()
+	ct := string(meta.componentType)
+	delete(ci.componentGraph, meta.ID())
+	if i, found := slices.BinarySearch(ci.sortedGraphIds, meta.ID()); found {
+		ci.sortedGraphIds = slices.Delete(ci.sortedGraphIds, i, i+1)
+	}
+	ci.componentGroupByType[ct] = slices.DeleteFunc(ci.componentGroupByType[ct], func(m *ComponentMeta[Component]) bool {
+		return m == meta
+	})
+	ci.typeIndex.remove(ct)
+	if ci.componentPrimaryGraph[ct] == meta {
+		delete(ci.componentPrimaryGraph, ct)
+	}
+}
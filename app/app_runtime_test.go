@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+type TestContextComponent struct {
+	SimpleComponent
+}
+
+func (c *TestContextComponent) InitWithContext(ctx context.Context, app *AppContext, conf *ConfContext) error {
+	return ctx.Err()
+}
+
+func TestRegisterAfterStartupTimeout(t *testing.T) {
+	app := App("demo")
+	app.WithStartupTimeout(time.Second)
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		if err := app.Register(&TestContextComponent{}); err != nil {
+			t.Errorf("runtime init should not use startup ctx: %v", err)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type TestRuntimeConnector struct {
+	SimpleRunnableComponent
+	Store *TestValidateStore `ekit:"component"`
+}
+
+func TestRuntimeRegister(t *testing.T) {
+	app := App("demo")
+	app.WithComponent(&TestValidateStore{})
+	connector := &TestRuntimeConnector{}
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		if err := app.RegisterNamed("tenant", connector); err != nil {
+			t.Error(err)
+			return
+		}
+		if connector.Store == nil {
+			t.Error("dependency should be injected from the live graph")
+		}
+		if err := app.Register(&TestStrictService{}); !errors.Is(err, ErrMissingDependency) {
+			t.Errorf("unexpected error %v", err)
+		}
+		storeId, _ := GetComponentId(app, connector.Store)
+		connectorId, _ := GetComponentId(app, connector)
+		for !slices.ContainsFunc(app.ComponentStates(), func(s ComponentStatus) bool {
+			return s.ID == connectorId && s.State == StateRunning
+		}) {
+			time.Sleep(time.Millisecond)
+		}
+		if status, err := app.ComponentState("TestRuntimeConnector:tenant"); err != nil || status.ID != connectorId {
+			t.Errorf("short name id should be resolved: %+v, %v", status, err)
+		}
+		if err := app.Unregister("TestValidateStore:testvalidatestore"); !errors.Is(err, ErrComponentInUse) {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err := app.UnregisterCascade(storeId); err != nil {
+			t.Error(err)
+		}
+		if app.GetComponentById(connectorId) != nil || app.GetComponentById(storeId) != nil {
+			t.Error("components should be unregistered")
+		}
+		if _, err := app.ComponentState(connectorId); !errors.Is(err, ErrComponentMetaNotFound) {
+			t.Errorf("unexpected error %v", err)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.app.Register(&TestValidateStore{}); !errors.Is(err, ErrAppNotRunning) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
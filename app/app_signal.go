@@ -0,0 +1,56 @@
+package app
+
+import (
+	"os"
+	"syscall"
+)
+
+type SignalAction func(app *AppContext, sig os.Signal)
+
+// SignalGracefulExit calls OnExit of runnable components and waits them stopped
+func SignalGracefulExit(app *AppContext, sig os.Signal) {
+	app.Exit("got signal " + sig.String() + ", stopping...")
+}
+
+// SignalImmediateExit stops waiting for runnable components without calling OnExit, then closes all components
+func SignalImmediateExit(app *AppContext, sig os.Signal) {
+	app.abort()
+}
+
+// SignalReloadConfig reloads config by all reloadable config loaders
+func SignalReloadConfig(app *AppContext, sig os.Signal) {
+	err := app.conf.Reload()
+	if err != nil {
+		app.MainLog.Error("failed to reload config:", err)
+		return
+	}
+	app.MainLog.Info("config reloaded")
+}
+
+func defaultSignalHandlers() map[os.Signal]SignalAction {
+	return map[os.Signal]SignalAction{
+		syscall.SIGHUP:  SignalReloadConfig,
+		syscall.SIGINT:  SignalGracefulExit,
+		syscall.SIGQUIT: SignalGracefulExit,
+		syscall.SIGTERM: SignalGracefulExit,
+	}
+}
+
+// WithSignalHandler replaces the action of sig, nil action stops handling sig.
+// By default SIGHUP reloads config, SIGINT, SIGQUIT and SIGTERM exit gracefully.
+func (r *RootComponent) WithSignalHandler(sig os.Signal, action SignalAction) {
+	if action == nil {
+		delete(r.signalHandlers, sig)
+		return
+	}
+	r.signalHandlers[sig] = action
+}
+
+func (r *RootComponent) handleSignal(sig os.Signal) {
+	action, ok := r.signalHandlers[sig]
+	if !ok {
+		return
+	}
+	r.logger.Infof("got signal: %v", sig)
+	action(r.app, sig)
+}
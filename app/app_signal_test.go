@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type TestCountingConfigLoader struct {
+	loads atomic.Int32
+}
+
+func (l *TestCountingConfigLoader) Load(updater *ConfigUpdater) error {
+	n := l.loads.Add(1)
+	return updater.UpdateConfig(&Conf{"loads": int(n)})
+}
+
+type TestReloadableConfigLoader struct {
+	TestCountingConfigLoader
+}
+
+func (l *TestReloadableConfigLoader) Reload(updater *ConfigUpdater) error {
+	return l.Load(updater)
+}
+
+func TestSignalHandlers(t *testing.T) {
+	app := App("demo")
+	loader := &TestReloadableConfigLoader{}
+	app.WithConfigLoader(loader)
+	var received []os.Signal
+	app.WithSignalHandler(syscall.SIGINT, func(app *AppContext, sig os.Signal) {
+		received = append(received, sig)
+	})
+	app.WithSignalHandler(syscall.SIGQUIT, nil)
+	var Server ComponentType = "SignalServer"
+	server := &SimpleRunnableComponent{}
+	app.WithComponentMeta("server", NewComponentMeta[Component](Server, server))
+	running, err := app.StartAsync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.handleSignal(syscall.SIGHUP)
+	if loads := running.App().conf.Value("loads").Int(); loads != 2 {
+		t.Fatalf("SIGHUP should reload config, got loads %d", loads)
+	}
+	app.handleSignal(syscall.SIGQUIT)
+	app.handleSignal(syscall.SIGINT)
+	if len(received) != 1 || received[0] != syscall.SIGINT {
+		t.Fatalf("unexpected received signals %v", received)
+	}
+	app.handleSignal(syscall.SIGTERM)
+	select {
+	case <-running.Done():
+	case <-time.After(time.Second):
+		t.Fatal("SIGTERM should exit gracefully")
+	}
+	if status, _ := running.App().ComponentState(getComponentID(Server, "server")); status.State != StateStopped {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
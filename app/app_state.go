@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ComponentState int
+
+const (
+	StateRegistered ComponentState = iota
+	StateInitializing
+	StateReady
+	StateRunning
+	StateStopping
+	StateStopped
+	StateFailed
+)
+
+func (s ComponentState) String() string {
+	switch s {
+	case StateRegistered:
+		return "registered"
+	case StateInitializing:
+		return "initializing"
+	case StateReady:
+		return "ready"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("ComponentState(%d)", int(s))
+}
+
+type ComponentStatus struct {
+	ID    string
+	Type  ComponentType
+	Name  string
+	State ComponentState
+	Err   error
+}
+
+func (cm *ComponentMeta[T]) State() ComponentState {
+	cm.stateLock.RLock()
+	defer cm.stateLock.RUnlock()
+	return cm.state
+}
+
+// LastError returns the last error which made the component failed
+func (cm *ComponentMeta[T]) LastError() error {
+	cm.stateLock.RLock()
+	defer cm.stateLock.RUnlock()
+	return cm.lastErr
+}
+
+func (cm *ComponentMeta[T]) Status() ComponentStatus {
+	cm.stateLock.RLock()
+	defer cm.stateLock.RUnlock()
+	return ComponentStatus{
+		ID:    cm.componentID,
+		Type:  cm.componentType,
+		Name:  cm.componentName,
+		State: cm.state,
+		Err:   cm.lastErr,
+	}
+}
+
+func (cm *ComponentMeta[T]) setState(state ComponentState, err error) {
+	cm.stateLock.Lock()
+	defer cm.stateLock.Unlock()
+	cm.state = state
+	if err != nil {
+		cm.lastErr = err
+	}
+}
+
+// fail marks component failed when err is not nil and returns err
+func (cm *ComponentMeta[T]) fail(err error) error {
+	if err != nil {
+		cm.setState(StateFailed, err)
+	}
+	return err
+}
+
+func (a *AppContext) ComponentState(id string) (ComponentStatus, error) {
+	a.lock.RLock()
+	id, err := a.resolveID(id)
+	meta, ok := a.registered[id]
+	a.lock.RUnlock()
+	if err != nil {
+		return ComponentStatus{}, err
+	}
+	if !ok {
+		return ComponentStatus{}, ErrComponentMetaNotFound
+	}
+	return meta.Status(), nil
+}
+
+// ComponentStates returns a snapshot of all registered components sorted by id
+func (a *AppContext) ComponentStates() []ComponentStatus {
+	a.lock.RLock()
+	result := make([]ComponentStatus, 0, len(a.registered))
+	for _, meta := range a.registered {
+		result = append(result, meta.Status())
+	}
+	a.lock.RUnlock()
+	slices.SortFunc(result, func(a, b ComponentStatus) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type TestStateProbe struct {
+	SimpleRunnableComponent
+	observed []ComponentStatus
+}
+
+func (p *TestStateProbe) Run(app *AppContext, conf *ConfContext) error {
+	p.observed = app.ComponentStates()
+	return nil
+}
+
+func TestComponentStates(t *testing.T) {
+	app := App("demo")
+	var Probe, Plain ComponentType = "Probe", "Plain"
+	probe := &TestStateProbe{}
+	app.WithComponentMeta("probe", NewComponentMeta[Component](Probe, probe))
+	app.WithComponentMeta("plain", NewComponentMeta[Component](Plain, &SimpleComponent{}))
+	if code := app.Start(); code != 0 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	if len(probe.observed) != 2 || probe.observed[0].State != StateReady || probe.observed[1].State != StateRunning {
+		t.Fatalf("unexpected states while running: %+v", probe.observed)
+	}
+	for _, status := range app.app.ComponentStates() {
+		if status.State != StateStopped {
+			t.Fatalf("component should be stopped after exit: %+v", status)
+		}
+	}
+	if _, err := app.app.ComponentState("unknown:id"); !errors.Is(err, ErrComponentMetaNotFound) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestComponentStateFailed(t *testing.T) {
+	app := App("demo")
+	var Hanging ComponentType = "FailedHanging"
+	app.WithComponentMeta("hanging", NewComponentMeta[Component](Hanging, &TestHangingComponent{}, WithInitTimeout[Component](10*time.Millisecond)))
+	if code := app.Start(); code != 4 {
+		t.Fatalf("unexpected exit code %d", code)
+	}
+	status, err := app.app.ComponentState(getComponentID(Hanging, "hanging"))
+	if err != nil || status.State != StateFailed || !errors.Is(status.Err, ErrInitTimeout) {
+		t.Fatalf("unexpected status %+v, %v", status, err)
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultRestartBackoff    = time.Second
+	DefaultMaxRestartBackoff = time.Minute
+)
+
+type RestartPolicy int
+
+const (
+	// RestartNever keeps the component stopped after Run returns
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure restarts the component when Run returns an error or panics
+	RestartOnFailure
+	// RestartAlways restarts the component whenever Run returns until app exits
+	RestartAlways
+)
+
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	}
+	return fmt.Sprintf("RestartPolicy(%d)", int(p))
+}
+
+type restartConf struct {
+	policy          RestartPolicy
+	maxRestarts     int
+	backoff         time.Duration
+	maxBackoff      time.Duration
+	window          time.Duration
+	exitOnExhausted bool
+}
+
+func (rc restartConf) delay(restarts int) time.Duration {
+	d := rc.backoff
+	if d <= 0 {
+		d = DefaultRestartBackoff
+	}
+	maxDelay := rc.maxBackoff
+	if maxDelay <= 0 {
+		maxDelay = DefaultMaxRestartBackoff
+	}
+	for i := 1; i < restarts && d < maxDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
+
+func WithRestartPolicy[T Component](policy RestartPolicy) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.restart.policy = policy
+	}
+}
+
+// WithMaxRestarts limits restarts of component, 0 means no limit
+func WithMaxRestarts[T Component](n int) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.restart.maxRestarts = n
+	}
+}
+
+// WithRestartBackoff waits initial before the first restart and doubles the delay for every following restart until max
+func WithRestartBackoff[T Component](initial, max time.Duration) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.restart.backoff = initial
+		meta.restart.maxBackoff = max
+	}
+}
+
+// WithRestartWindow resets the restart counter once the component has been running longer than d
+func WithRestartWindow[T Component](d time.Duration) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.restart.window = d
+	}
+}
+
+// WithExitOnRestartExhausted exits app when the component stops and no restart is left
+func WithExitOnRestartExhausted[T Component](meta *ComponentMeta[T]) {
+	meta.restart.exitOnExhausted = true
+}
+
+func (r *RootComponent) supervise(meta *ComponentMeta[Component], rc RunnableComponent) error {
+	conf := meta.restart
+	restarts := 0
+	for {
+		startAt := time.Now()
+		meta.setState(StateRunning, nil)
+		r.profiler.instant(profileRun, meta.ID())
+		err := meta.fail(runOnce(meta, rc, r.app, r.conf))
+		if err == nil {
+			meta.setState(StateStopped, nil)
+		}
+		if r.app.isExiting() || meta.removed.Load() {
+			return err
+		}
+		if conf.policy == RestartNever || (conf.policy == RestartOnFailure && err == nil) {
+			return err
+		}
+		if conf.window > 0 && time.Since(startAt) > conf.window {
+			restarts = 0
+		}
+		if conf.maxRestarts > 0 && restarts >= conf.maxRestarts {
+			err = errors.Join(fmt.Errorf("component[%s] stopped after %d restarts", meta.ID(), restarts), err)
+			if conf.exitOnExhausted {
+				r.logger.Error(err)
+				r.app.Exit("component " + meta.ID() + " exhausted restarts")
+			}
+			return err
+		}
+		restarts++
+		delay := conf.delay(restarts)
+		r.logger.Warnf("component[%s] stopped: %v, restart(%d) in %v", meta.ID(), err, restarts, delay)
+		select {
+		case <-time.After(delay):
+		case <-r.app.exitingCh:
+			return err
+		}
+		if meta.removed.Load() {
+			return err
+		}
+	}
+}
+
+func runOnce(meta *ComponentMeta[Component], rc RunnableComponent, app *AppContext, conf *ConfContext) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("component[%s] panic at running:%v", meta.ID(), e)
+		}
+	}()
+	return rc.Run(app, conf)
+}
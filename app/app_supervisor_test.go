@@ -0,0 +1,58 @@
+package app
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type TestFailingRunnable struct {
+	SimpleComponent
+	failures int
+	runs     atomic.Int32
+}
+
+func (f *TestFailingRunnable) Run(app *AppContext, conf *ConfContext) error {
+	n := f.runs.Add(1)
+	if int(n) <= f.failures {
+		panic("run failed")
+	}
+	return nil
+}
+func (f *TestFailingRunnable) OnExit() error {
+	return nil
+}
+
+func TestRestartOnFailure(t *testing.T) {
+	app := App("demo")
+	var Flaky ComponentType = "Flaky"
+	flaky := &TestFailingRunnable{failures: 2}
+	app.WithComponentMeta("flaky", NewComponentMeta[Component](Flaky, flaky,
+		WithRestartPolicy[Component](RestartOnFailure),
+		WithRestartBackoff[Component](time.Millisecond, 5*time.Millisecond)))
+	if code := app.Start(); code != 0 {
+		t.Fatalf("component should recover after restarts, got exit code %d", code)
+	}
+	if runs := flaky.runs.Load(); runs != 3 {
+		t.Fatalf("expected 3 runs, got %d", runs)
+	}
+}
+
+func TestRestartExhaustedExit(t *testing.T) {
+	app := App("demo")
+	var Broken, Idle ComponentType = "Broken", "Idle"
+	broken := &TestFailingRunnable{failures: 100}
+	idle := &SimpleRunnableComponent{}
+	app.WithComponentMeta("broken", NewComponentMeta[Component](Broken, broken,
+		WithRestartPolicy[Component](RestartAlways),
+		WithMaxRestarts[Component](2),
+		WithRestartBackoff[Component](time.Millisecond, time.Millisecond),
+		WithExitOnRestartExhausted[Component]))
+	app.WithComponentMeta("idle", NewComponentMeta[Component](Idle, idle))
+	if code := app.Start(); code != 5 {
+		t.Fatalf("exhausted restarts should fail with exit code 5, got %d", code)
+	}
+	if runs := broken.runs.Load(); runs != 3 {
+		t.Fatalf("expected 3 runs, got %d", runs)
+	}
+}
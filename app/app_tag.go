@@ -13,6 +13,9 @@ const (
 
 	TagComponent = "component"
 	TagRequired  = "required"
+	TagConfig    = "config"
+	TagParam     = "param"
+	TagDefault   = "default"
 )
 
 type EkitTagStr string
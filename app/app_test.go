@@ -87,3 +87,73 @@ func TestComponentDependencies(t *testing.T) {
 	app.WithComponentMeta("d", md)
 	app.Start()
 }
+
+type TestExitTrigger struct {
+	SimpleRunnableComponent
+}
+
+func (e *TestExitTrigger) Run(app *AppContext, conf *ConfContext) error {
+	go app.Exit("test done")
+	return e.SimpleRunnableComponent.Run(app, conf)
+}
+
+func TestIsolatedApps(t *testing.T) {
+	var Shared ComponentType = "Shared"
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := App(name)
+			app.WithComponentMeta("shared", NewComponentMeta[Component](Shared, &SimpleComponent{}, WithSingleton[Component]))
+			if code := app.Start(); code != 0 {
+				t.Fatalf("apps should not share registration state, got exit code %d", code)
+			}
+		})
+	}
+}
+
+func TestRunStartError(t *testing.T) {
+	app := App("demo")
+	var E, F ComponentType = "RunE", "RunF"
+	app.WithComponentMeta("e", NewComponentMeta(E, NewFakeComponent("E"), WithDependencyTypes[Component](F)))
+	app.WithComponentMeta("f", NewComponentMeta(F, NewFakeComponent("F"), WithDependencyTypes[Component](E)))
+	err := app.Run()
+	var se *StartError
+	if !errors.As(err, &se) || !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if se.Phase != PhaseInit || se.ComponentID == "" || se.ExitCode() != 4 {
+		t.Fatalf("unexpected start error %+v", se)
+	}
+
+	app = App("demo")
+	var Missing ComponentType = "RunMissing"
+	app.WithComponentMeta("missing", NewComponentMeta(Missing, NewFakeComponent("M"), WithDependencies[Component]("Unknown:unknown")))
+	err = app.Run()
+	if !errors.As(err, &se) || !errors.Is(err, ErrMissingDependency) || se.ComponentID != getComponentID(Missing, "missing") {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	app = App("demo")
+	var Broken ComponentType = "RunBroken"
+	app.WithComponentMeta("broken", NewComponentMeta[Component](Broken, &TestFailingRunnable{failures: 1}))
+	err = app.Run()
+	if !errors.As(err, &se) || se.Phase != PhaseRun || se.ComponentID != getComponentID(Broken, "broken") || se.ExitCode() != 5 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func testID(c Component) string {
+	t := ComponentTypeOf(c)
+	return getComponentID(t, t.ShortName())
+}
+
+type TestLazyCaller struct {
+	SimpleRunnableComponent
+	call func(app *AppContext)
+}
+
+func (l *TestLazyCaller) Run(app *AppContext, conf *ConfContext) error {
+	l.call(app)
+	go app.Exit("test done")
+	return l.SimpleRunnableComponent.Run(app, conf)
+}
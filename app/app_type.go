@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ComponentTypeOf returns the type of component registered by WithComponent
+func ComponentTypeOf(c Component) ComponentType {
+	t := reflect.TypeOf(c)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return ComponentType(qualifiedTypeName(t))
+}
+
+// ShortName returns the type name without package path
+func (t ComponentType) ShortName() string {
+	s := string(t)
+	end := len(s)
+	// package path of type parameters is kept
+	if i := strings.Index(s, "["); i >= 0 {
+		end = i
+	}
+	return s[strings.LastIndex(s[:end], ".")+1:]
+}
+
+// qualifiedTypeName returns the package path qualified name of t, so types with same name in different packages do not collide
+func qualifiedTypeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	if t.PkgPath() == "" {
+		return t.Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// typeIndex counts components by type and indexes types by short name, so short names resolve without scanning all types
+type typeIndex struct {
+	count map[string]int
+	short map[string][]string
+}
+
+func newTypeIndex(graph map[string]*ComponentMeta[Component]) *typeIndex {
+	x := &typeIndex{count: map[string]int{}, short: map[string][]string{}}
+	for _, meta := range graph {
+		x.add(string(meta.componentType))
+	}
+	return x
+}
+
+func (x *typeIndex) add(t string) {
+	x.count[t]++
+	if x.count[t] == 1 {
+		short := ComponentType(t).ShortName()
+		x.short[short] = append(x.short[short], t)
+	}
+}
+
+func (x *typeIndex) remove(t string) {
+	if x.count[t] == 0 {
+		return
+	}
+	x.count[t]--
+	if x.count[t] > 0 {
+		return
+	}
+	delete(x.count, t)
+	short := ComponentType(t).ShortName()
+	x.short[short] = slices.DeleteFunc(x.short[short], func(s string) bool {
+		return s == t
+	})
+	if len(x.short[short]) == 0 {
+		delete(x.short, short)
+	}
+}
+
+func (x *typeIndex) known(t string) bool {
+	return x.count[t] > 0
+}
+
+// resolveType maps t to a known type, a short name matches the qualified type having it when there is only one,
+// a qualified name matches the type registered by short name, unknown t is returned as it is
+func (x *typeIndex) resolveType(t string) (string, error) {
+	if x.known(t) {
+		return t, nil
+	}
+	short := ComponentType(t).ShortName()
+	if short != t {
+		if x.known(short) {
+			return short, nil
+		}
+		return t, nil
+	}
+	var matches []string
+	for _, candidate := range x.short[t] {
+		if candidate != t {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return t, nil
+	case 1:
+		return matches[0], nil
+	}
+	slices.Sort(matches)
+	return t, fmt.Errorf("%w: %s matches %s", ErrAmbiguousComponentType, t, strings.Join(matches, ", "))
+}
+
+// resolveID resolves the type part of id by resolveType
+func (x *typeIndex) resolveID(id string) (string, error) {
+	t, name, ok := strings.Cut(id, ":")
+	if !ok {
+		return id, nil
+	}
+	rt, err := x.resolveType(t)
+	if err != nil {
+		return id, err
+	}
+	return rt + ":" + name, nil
+}
+
+func (ci *ComponentInitializer) resolveType(t string) (string, error) {
+	return ci.typeIndex.resolveType(t)
+}
+
+func (ci *ComponentInitializer) resolveID(id string) (string, error) {
+	if _, ok := ci.componentGraph[id]; ok {
+		return id, nil
+	}
+	return ci.typeIndex.resolveID(id)
+}
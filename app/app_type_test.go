@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypeIndex(t *testing.T) {
+	x := newTypeIndex(nil)
+	x.add("a.Client")
+	x.add("a.Client")
+	x.add("b.Client")
+	if _, err := x.resolveType("Client"); !errors.Is(err, ErrAmbiguousComponentType) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	x.remove("b.Client")
+	if rt, err := x.resolveID("Client:main"); err != nil || rt != "a.Client:main" {
+		t.Fatalf("unexpected id %s, %v", rt, err)
+	}
+	x.remove("a.Client")
+	if rt, _ := x.resolveType("Client"); rt != "a.Client" {
+		t.Fatalf("type with remaining components should be kept, got %s", rt)
+	}
+	x.remove("a.Client")
+	if rt, _ := x.resolveType("Client"); rt != "Client" || x.known("a.Client") {
+		t.Fatalf("removed type should not be resolved, got %s", rt)
+	}
+}
+
+func TestQualifiedComponentType(t *testing.T) {
+	if ComponentTypeOf(&TestValidateStore{}) != "github.com/wyx0k/ekit/app.TestValidateStore" {
+		t.Fatalf("unexpected type %s", ComponentTypeOf(&TestValidateStore{}))
+	}
+	var CacheClient, DbClient ComponentType = "example.com/cache.Client", "example.com/db.Client"
+	newApp := func() *RootComponent {
+		app := App("demo")
+		app.WithComponentMeta("client", NewComponentMeta[Component](CacheClient, &SimpleComponent{}, WithSingleton[Component]))
+		app.WithComponentMeta("client", NewComponentMeta[Component](DbClient, &SimpleComponent{}, WithSingleton[Component]))
+		return app
+	}
+	app := newApp()
+	app.WithComponentMeta("user", NewComponentMeta[Component]("User", &SimpleComponent{}, WithDependencyTypes[Component]("Client")))
+	if err := app.Validate(); !errors.Is(err, ErrAmbiguousComponentType) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	app = newApp()
+	store := &TestValidateStore{}
+	app.WithComponent(store)
+	app.WithComponent(&TestLazyCaller{call: func(app *AppContext) {
+		if _, err := app.GetComponentByIdE("Client:client"); !errors.Is(err, ErrAmbiguousComponentType) {
+			t.Errorf("unexpected error %v", err)
+		}
+		if c, err := app.GetComponentByIdE(getComponentID(DbClient, "client")); err != nil || c == nil {
+			t.Errorf("unexpected component %v, %v", c, err)
+		}
+		if c := app.GetComponent("TestValidateStore", "TestValidateStore"); c != store {
+			t.Errorf("unique short name should be resolved, got %v", c)
+		}
+	}})
+	if err := app.Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -10,9 +10,9 @@ var ErrComponentMetaNotFound = errors.New("the component meta info not found")
 
 func GetComponentById[T Component](app *AppContext, id string) (T, error) {
 	var t T
-	c := app.GetComponentById(id)
-	if c == nil {
-		return t, ErrComponentNotFound
+	c, err := app.GetComponentByIdE(id)
+	if err != nil {
+		return t, err
 	}
 	if tc, ok := c.(T); ok {
 		return tc, nil
@@ -23,9 +23,9 @@ func GetComponentById[T Component](app *AppContext, id string) (T, error) {
 
 func GetSingletonComponentByType[T Component](app *AppContext, componentType ComponentType) (T, error) {
 	var t T
-	c := app.GetSingletonComponent(string(componentType))
-	if c == nil {
-		return t, ErrComponentNotFound
+	c, err := app.GetSingletonComponentE(string(componentType))
+	if err != nil {
+		return t, err
 	}
 	if tc, ok := c.(T); ok {
 		return tc, nil
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Validate checks the wiring of registered components without loading config or calling Init,
+// all duplicated components, missing dependencies, ambiguous injections and circular dependencies are reported at once.
+// Conditions are not evaluated, components with conditions are taken as alternatives which are never kept together
+func (r *RootComponent) Validate() error {
+	var errs []error
+	for _, err := range r.setupComponentErr {
+		errs = append(errs, newStartError(PhaseSetup, "", err))
+	}
+	errs = append(errs, r.checkDuplicates()...)
+	components := r.allComponents()
+	m, primary, groupErrs := groupComponents(components)
+	errs = append(errs, groupErrs...)
+	ci := &ComponentInitializer{
+		componentGraph:        components,
+		componentGroupByType:  m,
+		componentPrimaryGraph: primary,
+		sortedGraphIds:        sortedIds(components),
+		typeIndex:             newTypeIndex(components),
+	}
+	errs = append(errs, ci.validate()...)
+	return joinErrors(errs)
+}
+
+func (ci *ComponentInitializer) validate() []error {
+	var errs []error
+	for _, id := range ci.sortedGraphIds {
+		meta := ci.componentGraph[id]
+		if _, err := ci.dependenciesOf(meta); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, ci.validateInjection(meta)...)
+	}
+	return append(errs, ci.circularDependencies()...)
+}
+
+func (ci *ComponentInitializer) validateInjection(meta *ComponentMeta[Component]) []error {
+	var errs []error
+	typeName := reflect.TypeOf(meta.component).Elem().Name()
+	for _, diInfo := range meta.injections() {
+		if !diInfo.IsDependAll && diInfo.DependIface == nil {
+			// missing instances are reported as missing dependencies
+			continue
+		}
+		candidates := ci.candidates(meta, diInfo)
+		if len(candidates) == 0 && diInfo.Required {
+			errs = append(errs, newStartError(PhaseInit, meta.ID(), fmt.Errorf("%w: %s.%s can not set, found 0 candidates, but field is required", ErrMissingDependency, typeName, diInfo.FieldName)))
+		}
+		if alternatives(candidates) > 1 && (diInfo.FieldKind == reflect.Ptr || diInfo.FieldKind == reflect.Interface) {
+			if ci.primaryOf(diInfo, candidates) == nil {
+				errs = append(errs, newStartError(PhaseInit, meta.ID(), fmt.Errorf("%s.%s can not set, %w, please specify name on tag or set primary when register component", typeName, diInfo.FieldName, ErrAmbiguousDependency)))
+			}
+		}
+	}
+	return errs
+}
+
+// alternatives counts candidates which may be kept together, components with conditions count as one
+func alternatives(candidates []*ComponentMeta[Component]) int {
+	n, conditional := 0, 0
+	for _, meta := range candidates {
+		if len(meta.conditions) > 0 {
+			conditional = 1
+		} else {
+			n++
+		}
+	}
+	return n + conditional
+}
+
+// circularDependencies reports every distinct cycle of the graph
+func (ci *ComponentInitializer) circularDependencies() []error {
+	var errs []error
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	color := map[string]int{}
+	reported := map[string]struct{}{}
+	var chain []string
+	var visit func(meta *ComponentMeta[Component])
+	visit = func(meta *ComponentMeta[Component]) {
+		color[meta.ID()] = visiting
+		chain = append(chain, meta.ID())
+		deps, _ := ci.dependenciesOf(meta)
+		for _, dep := range deps {
+			switch color[dep.ID()] {
+			case visiting:
+				cycle := slices.Clone(chain[slices.Index(chain, dep.ID()):])
+				key := slices.Clone(cycle)
+				slices.Sort(key)
+				if _, ok := reported[strings.Join(key, ",")]; ok {
+					continue
+				}
+				reported[strings.Join(key, ",")] = struct{}{}
+				cycle = append(cycle, dep.ID())
+				errs = append(errs, newStartError(PhaseInit, dep.ID(), fmt.Errorf("%w found:%s", ErrCircularDependency, strings.Join(cycle, " -> "))))
+			case 0:
+				visit(dep)
+			}
+		}
+		chain = chain[:len(chain)-1]
+		color[meta.ID()] = visited
+	}
+	for _, id := range ci.sortedGraphIds {
+		if color[id] == 0 {
+			visit(ci.componentGraph[id])
+		}
+	}
+	return errs
+}
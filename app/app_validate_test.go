@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type TestValidateStore struct {
+	SimpleComponent
+}
+
+type TestValidateService struct {
+	SimpleComponent
+	Store *TestValidateStore `ekit:"component"`
+}
+
+func TestValidate(t *testing.T) {
+	app := App("demo")
+	app.WithNamedComponent("a", &TestValidateStore{})
+	app.WithNamedComponent("b", &TestValidateStore{})
+	app.WithComponent(&TestValidateService{})
+	var E, F, G, H ComponentType = "ValidateE", "ValidateF", "ValidateG", "ValidateH"
+	app.WithComponentMeta("e", NewComponentMeta(E, NewFakeComponent("E"), WithDependencyTypes[Component](F)))
+	app.WithComponentMeta("f", NewComponentMeta(F, NewFakeComponent("F"), WithDependencyTypes[Component](E)))
+	app.WithComponentMeta("g", NewComponentMeta(G, NewFakeComponent("G"), WithDependencyTypes[Component](H)))
+	app.WithComponentMeta("h", NewComponentMeta(H, NewFakeComponent("H"), WithDependencyTypes[Component](G), WithDependencies[Component]("Unknown:unknown")))
+	err := app.Validate()
+	if !errors.Is(err, ErrAmbiguousDependency) || !errors.Is(err, ErrMissingDependency) || !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if count := strings.Count(err.Error(), "circular dependencies found"); count != 2 {
+		t.Fatalf("both cycles should be reported: %v", err)
+	}
+
+	app = App("demo")
+	app.WithComponent(&TestValidateStore{})
+	app.WithComponent(&TestValidateService{})
+	app.WithComponentMeta("hanging", NewComponentMeta[Component]("ValidateHanging", &TestHangingComponent{}))
+	if err = app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type TestValidateCache interface {
+	Cached() bool
+}
+
+type TestValidateRedis struct {
+	SimpleComponent
+}
+
+func (c *TestValidateRedis) Cached() bool {
+	return true
+}
+
+type TestValidateMemory struct {
+	SimpleComponent
+}
+
+func (c *TestValidateMemory) Cached() bool {
+	return true
+}
+
+type TestValidateCacheUser struct {
+	SimpleComponent
+	Cache TestValidateCache `ekit:"component"`
+}
+
+func TestValidateConditions(t *testing.T) {
+	app := App("demo")
+	app.WithComponent(&TestValidateRedis{}, WithCondition[Component](OnConfig("cache.type", "redis")))
+	app.WithComponent(&TestValidateMemory{}, WithCondition[Component](OnMissingComponent("TestValidateRedis")))
+	app.WithComponent(&TestValidateCacheUser{})
+	if err := app.Validate(); err != nil {
+		t.Fatalf("components with conditions should be alternatives: %v", err)
+	}
+
+	app = App("demo")
+	app.WithComponent(&TestValidateRedis{})
+	app.WithComponent(&TestValidateMemory{}, WithCondition[Component](OnMissingComponent("TestValidateRedis")))
+	app.WithComponent(&TestValidateCacheUser{})
+	if err := app.Validate(); !errors.Is(err, ErrAmbiguousDependency) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
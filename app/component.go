@@ -1,11 +1,19 @@
 package app
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+var ErrInitTimeout = errors.New("component init timeout")
+
 type DependenciesExtendComponent interface {
 	EkitDependencies() (types []ComponentType, instances []string)
 }
@@ -25,6 +33,13 @@ type Component interface {
 	Init(app *AppContext, conf *ConfContext) error
 	Close() error
 }
+
+// ContextInitComponent will be initialized by InitWithContext instead of Init,
+// ctx is canceled when the init timeout of component or the startup timeout of app expires
+type ContextInitComponent interface {
+	Component
+	InitWithContext(ctx context.Context, app *AppContext, conf *ConfContext) error
+}
 type ComponentType string
 
 type ComponentMeta[T Component] struct {
@@ -34,13 +49,34 @@ type ComponentMeta[T Component] struct {
 	dependencyTypes   []string
 	dependencies      []string
 	additionalDepends map[string]struct{}
+	extendDepends     map[string]struct{}
 	fieldInfo         map[string]fieldInfo
+	configFields      []configField
+	conditions        []Condition
+	setupErr          error
+	module            string
 	singleton         bool
 	primary           bool
 	ignoreError       bool
 	lazyInit          bool
+	initTimeout       time.Duration
+	healthTimeout     time.Duration
+	healthCacheTTL    time.Duration
+	nonCritical       bool
+	restart           restartConf
+	state             ComponentState
+	lastErr           error
+	stateLock         sync.RWMutex
+	removed           atomic.Bool
+	factory           func() T
+	provider          *provider
+	instanceSeq       atomic.Int64
+	templateUsed      atomic.Bool
+	fetched           bool
+	lazyLock          sync.Mutex
+	lazyErr           error
 	_initialized      bool
-	_lazy_initialized bool
+	_lazy_initialized atomic.Bool
 
 	component T
 }
@@ -55,6 +91,7 @@ func WithPrimary[T Component](meta *ComponentMeta[T]) {
 	meta.primary = true
 }
 
+// WithIgnoreError skips the component when its init fails, it is reported not ready by Health
 func WithIgnoreError[T Component](meta *ComponentMeta[T]) {
 	meta.ignoreError = true
 }
@@ -63,6 +100,19 @@ func WithLazyInit[T Component](meta *ComponentMeta[T]) {
 	meta.lazyInit = true
 }
 
+// WithPrototype builds a new instance by factory for every injection point and every GetComponent call
+func WithPrototype[T Component](factory func() T) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.factory = factory
+	}
+}
+
+func WithInitTimeout[T Component](d time.Duration) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		meta.initTimeout = d
+	}
+}
+
 func WithDependencyTypes[T Component](types ...ComponentType) ComponentMetaOption[T] {
 	return func(meta *ComponentMeta[T]) {
 		for _, d := range types {
@@ -98,6 +148,18 @@ func withDependencies[T Component](dependencies ...string) ComponentMetaOption[T
 		meta.dependencies = lst
 	}
 }
+
+// withExtendDependencies records dependencies declared by EkitDependencies
+func withExtendDependencies[T Component](types []ComponentType, instances []string) ComponentMetaOption[T] {
+	return func(meta *ComponentMeta[T]) {
+		for _, t := range types {
+			meta.extendDepends[string(t)] = struct{}{}
+		}
+		for _, id := range instances {
+			meta.extendDepends[id] = struct{}{}
+		}
+	}
+}
 func withFieldInfo[T Component](fieldMap map[string]fieldInfo) ComponentMetaOption[T] {
 	return func(meta *ComponentMeta[T]) {
 		meta.fieldInfo = fieldMap
@@ -109,10 +171,13 @@ func NewComponentMeta[T Component](componentType ComponentType, component T, opt
 		componentType:     componentType,
 		component:         component,
 		additionalDepends: map[string]struct{}{},
+		extendDepends:     map[string]struct{}{},
 	}
 	for _, option := range options {
 		option(cm)
 	}
+	// reported by preInit
+	cm.configFields, cm.setupErr = resolveConfigFields(component)
 	return cm
 }
 
@@ -120,12 +185,18 @@ func (cm *ComponentMeta[T]) preInit(name string) error {
 	if cm == nil {
 		return errors.New("component-meta must not be nil")
 	}
+	if cm.setupErr != nil {
+		return cm.setupErr
+	}
 	v := reflect.ValueOf(cm.component)
 	if v.Kind() == reflect.Ptr {
 		if !v.IsValid() || v.IsNil() {
 			return errors.New("component in component-meta must not be nil")
 		}
 	}
+	if _, ok := any(cm.component).(RunnableComponent); ok && cm.IsPrototype() {
+		return errors.New("prototype component must not be runnable")
+	}
 	name = strings.ToLower(name)
 	cm.componentName = name
 	cm.componentID = getComponentID(cm.componentType, name)
@@ -150,6 +221,27 @@ func (cm *ComponentMeta[T]) Dependencies() []string {
 func (cm *ComponentMeta[T]) fieldMap() map[string]fieldInfo {
 	return cm.fieldInfo
 }
+
+// injections returns injected fields sorted by name and then parameters of provider
+func (cm *ComponentMeta[T]) injections() []fieldInfo {
+	names := make([]string, 0, len(cm.fieldInfo))
+	for name := range cm.fieldInfo {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var infos []fieldInfo
+	for _, name := range names {
+		infos = append(infos, cm.fieldInfo[name])
+	}
+	if cm.provider != nil {
+		for _, param := range cm.provider.params {
+			if param.DependType != "" || param.DependIface != nil {
+				infos = append(infos, param)
+			}
+		}
+	}
+	return infos
+}
 func (cm *ComponentMeta[T]) IsSingleton() bool {
 	return cm.singleton
 }
@@ -159,56 +251,161 @@ func (cm *ComponentMeta[T]) IsPrimary() bool {
 func (cm *ComponentMeta[T]) IsIgnoreError() bool {
 	return cm.ignoreError
 }
+func (cm *ComponentMeta[T]) IsPrototype() bool {
+	return cm.factory != nil
+}
 func (cm *ComponentMeta[T]) IsLazyInit() bool {
 	return cm.lazyInit
 }
+func (cm *ComponentMeta[T]) InitTimeout() time.Duration {
+	return cm.initTimeout
+}
 func (cm *ComponentMeta[T]) IsInitialized() bool {
 	return cm._initialized
 }
+
+// isSkipped reports whether the component failed to init but ignores error
+func (cm *ComponentMeta[T]) isSkipped() bool {
+	return cm.ignoreError && cm.State() == StateFailed
+}
 func (cm *ComponentMeta[T]) IsAdditionalDepends(depend string) bool {
 	_, ok := cm.additionalDepends[depend]
 	return ok
 }
+func (cm *ComponentMeta[T]) IsExtendDepends(depend string) bool {
+	_, ok := cm.extendDepends[depend]
+	return ok
+}
 func (cm *ComponentMeta[T]) IsLazyInitialized() bool {
-	return cm._lazy_initialized
+	return cm._lazy_initialized.Load()
 }
 
-func (cm *ComponentMeta[T]) init(app *AppContext, conf *ConfContext) error {
-	if cm.IsLazyInit() {
+func (cm *ComponentMeta[T]) init(ctx context.Context, app *AppContext, conf *ConfContext) error {
+	if cm.IsLazyInit() || cm.IsPrototype() {
 		cm._initialized = true
 		return nil
 	}
-	err := cm.component.Init(app, conf)
+	cm.setState(StateInitializing, nil)
+	err := cm.callInit(ctx, app, conf)
 	if err != nil {
-		return errors.New("[" + cm.componentID + "] " + err.Error())
+		return cm.fail(err)
 	}
 	cm._initialized = true
+	cm.setState(StateReady, nil)
 	return nil
 }
-func (cm *ComponentMeta[T]) lazyinit(app *AppContext, conf *ConfContext) error {
-	if !cm.lazyInit {
-		return nil
+
+// lazyinit runs Init exactly once, concurrent callers block until it finishes and share its error,
+// done reports whether this call performed the init
+func (cm *ComponentMeta[T]) lazyinit(ctx context.Context, app *AppContext, conf *ConfContext) (done bool, err error) {
+	if !cm.lazyInit || cm._lazy_initialized.Load() {
+		return false, nil
+	}
+	cm.lazyLock.Lock()
+	defer cm.lazyLock.Unlock()
+	if cm._lazy_initialized.Load() || cm.lazyErr != nil {
+		return false, cm.lazyErr
 	}
-	err := cm.component.Init(app, conf)
+	cm.setState(StateInitializing, nil)
+	err = cm.callInit(ctx, app, conf)
 	if err != nil {
+		cm.lazyErr = cm.fail(err)
+		return true, cm.lazyErr
+	}
+	cm._lazy_initialized.Store(true)
+	cm.setState(StateReady, nil)
+	return true, nil
+}
+
+// callInit stops waiting for the component once ctx is done, the init goroutine of a hanging component is abandoned
+func (cm *ComponentMeta[T]) callInit(ctx context.Context, app *AppContext, conf *ConfContext) error {
+	if cm.initTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cm.initTimeout)
+		defer cancel()
+	}
+	doInit := func() error {
+		if c, ok := any(cm.component).(ContextInitComponent); ok {
+			return c.InitWithContext(ctx, app, conf)
+		}
+		return cm.component.Init(app, conf)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return doInit()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- doInit()
+	}()
+	select {
+	case err := <-done:
 		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %w", ErrInitTimeout, ctx.Err())
+		}
+		return ctx.Err()
 	}
-	cm._lazy_initialized = true
-	return nil
 }
+
+// newInstance builds a not initialized instance of prototype component with options of the prototype,
+// the component registered with the prototype is the first instance, lazy init does not apply to instances
+func (cm *ComponentMeta[T]) newInstance() *ComponentMeta[T] {
+	component := cm.component
+	if !cm.templateUsed.CompareAndSwap(false, true) {
+		component = cm.factory()
+	}
+	return &ComponentMeta[T]{
+		componentID:       fmt.Sprintf("%s#%d", cm.componentID, cm.instanceSeq.Add(1)),
+		componentName:     cm.componentName,
+		componentType:     cm.componentType,
+		dependencyTypes:   cm.dependencyTypes,
+		dependencies:      cm.dependencies,
+		additionalDepends: cm.additionalDepends,
+		extendDepends:     cm.extendDepends,
+		fieldInfo:         cm.fieldInfo,
+		configFields:      cm.configFields,
+		conditions:        cm.conditions,
+		module:            cm.module,
+		singleton:         cm.singleton,
+		primary:           cm.primary,
+		ignoreError:       cm.ignoreError,
+		initTimeout:       cm.initTimeout,
+		healthTimeout:     cm.healthTimeout,
+		healthCacheTTL:    cm.healthCacheTTL,
+		nonCritical:       cm.nonCritical,
+		restart:           cm.restart,
+		component:         component,
+	}
+}
+
 func (cm *ComponentMeta[T]) close() error {
+	if cm.IsPrototype() {
+		return nil
+	}
 	if cm.IsLazyInit() {
-		if cm._lazy_initialized {
-			return cm.component.Close()
+		if cm._lazy_initialized.Load() {
+			return cm.closeComponent()
 		}
 	} else {
 		if cm._initialized {
-			return cm.component.Close()
+			return cm.closeComponent()
 		}
 	}
 	return nil
 }
 
+func (cm *ComponentMeta[T]) closeComponent() error {
+	err := cm.component.Close()
+	if err != nil {
+		return cm.fail(err)
+	}
+	if cm.State() != StateFailed {
+		cm.setState(StateStopped, nil)
+	}
+	return nil
+}
+
 func getComponentID(componentType ComponentType, componentName string) string {
 	componentName = strings.ToLower(componentName)
 	return string(componentType) + ":" + componentName
@@ -1,7 +1,11 @@
 package app
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/charmbracelet/log"
 	"github.com/fsnotify/fsnotify"
@@ -12,7 +16,10 @@ type FileConfig struct {
 	SourceName string
 	Filename   string
 	Filepath   string
+	lock       sync.Mutex
 	v          *viper.Viper
+	overlays   []*viper.Viper
+	profiles   []string
 	updater    *ConfigUpdater
 }
 
@@ -29,32 +36,103 @@ func NewFileConfigLoader(path string) *FileConfig {
 }
 
 func (f *FileConfig) Load(updater *ConfigUpdater) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.load(updater)
+}
+
+func (f *FileConfig) load(updater *ConfigUpdater) error {
 	v := viper.New()
 	v.SetConfigFile(f.Filepath)
 	if err := v.ReadInConfig(); err != nil {
 		return err
 	}
-	v.OnConfigChange(func(e fsnotify.Event) {
-		log.Infof("[config] %s changed:%s", f.Filename, e.Name)
-		c := Conf{}
-		err := v.Unmarshal(&c)
-		if err != nil {
-			log.Error(err)
-		}
-		updater.UpdateConfig(&c)
-	})
+	f.v = v
+	f.updater = updater
+	v.OnConfigChange(f.onChange)
 	v.WatchConfig()
-	c := Conf{}
-	err := v.Unmarshal(&c)
-	if err != nil {
+	if err := f.update(); err != nil {
 		log.Error(err)
 	}
-	f.v = v
-	f.updater = updater
-	updater.UpdateConfig(&c)
 	return nil
 }
 
+// ProfilePath returns the config file of profile next to the base file, like config-dev.yaml for config.yaml
+func (f *FileConfig) ProfilePath(profile string) string {
+	ext := filepath.Ext(f.Filepath)
+	return strings.TrimSuffix(f.Filepath, ext) + "-" + profile + ext
+}
+
+// LoadProfiles overlays config files of profiles on the base file in order, missing files are skipped
+func (f *FileConfig) LoadProfiles(updater *ConfigUpdater, profiles []string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.loadProfiles(updater, profiles)
+}
+
+func (f *FileConfig) loadProfiles(updater *ConfigUpdater, profiles []string) error {
+	f.updater = updater
+	f.profiles = profiles
+	f.overlays = nil
+	for _, profile := range profiles {
+		path := f.ProfilePath(profile)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			log.Warnf("[config] config file of profile %s not found: %s", profile, path)
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return err
+		}
+		v.OnConfigChange(f.onChange)
+		v.WatchConfig()
+		f.overlays = append(f.overlays, v)
+	}
+	return f.update()
+}
+
+func (f *FileConfig) onChange(e fsnotify.Event) {
+	log.Infof("[config] %s changed:%s", f.Filename, e.Name)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.update(); err != nil {
+		log.Error(err)
+	}
+}
+
+// update stores the base config and overlays of profiles, later ones win, f.lock must be held
+func (f *FileConfig) update() error {
+	confs := make([]*Conf, 0, len(f.overlays)+1)
+	for _, v := range append([]*viper.Viper{f.v}, f.overlays...) {
+		c := Conf{}
+		if err := v.Unmarshal(&c); err != nil {
+			return err
+		}
+		confs = append(confs, &c)
+	}
+	return f.updater.UpdateConfig(confs...)
+}
+
+// Reload reads the base file and the config files of profiles again
+func (f *FileConfig) Reload(updater *ConfigUpdater) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.v == nil {
+		if err := f.load(updater); err != nil || f.profiles == nil {
+			return err
+		}
+		return f.loadProfiles(updater, f.profiles)
+	}
+	for _, v := range append([]*viper.Viper{f.v}, f.overlays...) {
+		if err := v.ReadInConfig(); err != nil {
+			return err
+		}
+	}
+	f.updater = updater
+	return f.update()
+}
+
 func (f *FileConfig) Stop() error {
 	return nil
 }
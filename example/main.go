@@ -17,6 +17,7 @@ var appName = "demo"
 func main() {
 	var (
 		configFile string
+		check      bool
 		err        error
 	)
 	var rootCmd = &cobra.Command{
@@ -36,10 +37,19 @@ func main() {
 			demo.WithComponent(&Demo{})
 			demo.WithComponent(&Demo2{A: "demo2 -----> ok"})
 			demo.WithComponent(&RunnableDemo{})
+			if check {
+				if err = demo.Validate(); err != nil {
+					fmt.Println(err.Error())
+					os.Exit(1)
+				}
+				fmt.Println("all components wired successfully")
+				os.Exit(0)
+			}
 			os.Exit(demo.Start())
 		},
 	}
 	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "c", "./config.yaml", "config")
+	rootCmd.PersistentFlags().BoolVar(&check, "check", false, "check wiring of components without initializing them")
 	rootCmd.Execute()
 
 }